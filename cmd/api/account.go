@@ -0,0 +1,195 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"errors"
+	"net/http"
+)
+
+func (app *application) Register(w http.ResponseWriter, r *http.Request) {
+	var registerUser dtos.RegisterUser
+
+	err := app.readJSON(w, r, &registerUser)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := registerUser.Validate()
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	user := models.User{
+		FirstName: registerUser.FirstName,
+		LastName:  registerUser.LastName,
+		Email:     registerUser.Email,
+		Role:      models.RoleViewer,
+	}
+
+	err = user.SetPassword(registerUser.Password)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.DB.CreateUser(&user)
+
+	if errors.Is(err, repositories.ErrDuplicateEmail) {
+		app.validationErrorJSON(w, dtos.ValidationErrors{"email": err.Error()})
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	_ = app.writeJSON(w, http.StatusCreated, user)
+}
+
+func (app *application) GetMe(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, user)
+}
+
+func (app *application) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var updateUser dtos.UpdateUser
+
+	err = app.readJSON(w, r, &updateUser)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := updateUser.Validate()
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	if updateUser.FirstName != nil {
+		user.FirstName = *updateUser.FirstName
+	}
+
+	if updateUser.LastName != nil {
+		user.LastName = *updateUser.LastName
+	}
+
+	if updateUser.Email != nil {
+		user.Email = *updateUser.Email
+	}
+
+	err = app.DB.UpdateUser(user)
+
+	if errors.Is(err, repositories.ErrDuplicateEmail) {
+		app.validationErrorJSON(w, dtos.ValidationErrors{"email": err.Error()})
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, user)
+}
+
+func (app *application) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var changePassword dtos.ChangePassword
+
+	err = app.readJSON(w, r, &changePassword)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := changePassword.Validate()
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	valid, err := user.PasswordMatches(changePassword.CurrentPassword)
+
+	if err != nil || !valid {
+		app.validationErrorJSON(w, dtos.ValidationErrors{"current_password": "is incorrect"})
+		return
+	}
+
+	err = user.SetPassword(changePassword.NewPassword)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.DB.UpdateUserPassword(user.ID, user.Password)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	err = app.DB.RevokeUserRefreshTokens(user.ID)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	http.SetCookie(w, app.auth.GetExpiredRefreshCookie())
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "Password successfuly changed",
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func (app *application) currentUser(r *http.Request) (*models.User, error) {
+	userID, ok := userIDFromContext(r.Context())
+
+	if !ok {
+		return nil, errors.New("unauthorized")
+	}
+
+	user, err := app.DB.GetUserByID(userID)
+
+	if err != nil {
+		return nil, errors.New("unkown user")
+	}
+
+	return user, nil
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const apiKeyPrefix = "gm"
+
+func (app *application) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, _ := claimsFromContext(r.Context())
+	userID, _ := userIDFromContext(r.Context())
+
+	var createAPIKey dtos.CreateAPIKey
+
+	err := app.readJSON(w, r, &createAPIKey)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := createAPIKey.Validate()
+
+	if len(errs) == 0 && !models.RoleSatisfies(claims.Role, createAPIKey.Role) {
+		errs.Add("role", "must not exceed your own role")
+	}
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	key, prefix, err := generateAPIKey()
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	apiKey := models.APIKey{
+		UserID:  userID,
+		Name:    createAPIKey.Name,
+		Prefix:  prefix,
+		KeyHash: hashToken(key),
+		Role:    createAPIKey.Role,
+	}
+
+	if createAPIKey.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, createAPIKey.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	err = app.DB.CreateAPIKey(&apiKey)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	var payload = struct {
+		*models.APIKey
+		Key string `json:"key"`
+	}{
+		APIKey: &apiKey,
+		Key:    key,
+	}
+
+	_ = app.writeJSON(w, http.StatusCreated, payload)
+}
+
+func (app *application) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := app.DB.GetAPIKeys()
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, apiKeys)
+}
+
+func (app *application) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	err = app.DB.RevokeAPIKey(id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		app.errorJSON(w, errors.New("api key not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "API key successfuly revoked",
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func (app *application) authenticateAPIKey(key string) (*Claims, error) {
+	apiKey, err := app.DB.GetAPIKeyByHash(hashToken(key))
+
+	if err != nil || !apiKey.IsActive() {
+		return nil, errors.New("invalid api key")
+	}
+
+	err = app.DB.TouchAPIKey(apiKey.ID)
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return &Claims{
+		Role:     apiKey.EffectiveRole(),
+		APIKeyID: apiKey.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: fmt.Sprint(apiKey.UserID),
+		},
+	}, nil
+}
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	scheme, key, found := strings.Cut(r.Header.Get("Authorization"), " ")
+
+	if found && scheme == "ApiKey" {
+		return key
+	}
+
+	return ""
+}
+
+func generateAPIKey() (string, string, error) {
+	prefixBytes := make([]byte, 4)
+	secretBytes := make([]byte, 32)
+
+	_, err := rand.Read(prefixBytes)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = rand.Read(secretBytes)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix := hex.EncodeToString(prefixBytes)
+
+	return apiKeyPrefix + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secretBytes), prefix, nil
+}
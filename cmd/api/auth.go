@@ -2,6 +2,8 @@ package main
 
 import (
 	"backend/internal/models"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,59 +14,85 @@ import (
 )
 
 type Auth struct {
-	Issuer        string
-	Audience      string
-	Secret        string
-	TokenExpiry   time.Duration
-	RefreshExpiry time.Duration
-	CookieDomain  string
-	CookiePath    string
-	CookieName    string
+	Issuer             string
+	Audience           string
+	Secret             string
+	SigningKeys        []*SigningKey
+	AcceptLegacySecret bool
+	TokenExpiry        time.Duration
+	MFAExpiry          time.Duration
+	RefreshExpiry      time.Duration
+	CookieDomain       string
+	CookiePath         string
+	CookieName         string
 }
 
 type TokenPair struct {
-	Token        string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	Token            string    `json:"access_token"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshTokenID   string    `json:"-"`
+	FamilyID         string    `json:"-"`
+	RefreshExpiresAt time.Time `json:"-"`
 }
 
 type Claims struct {
+	Role       string `json:"role"`
+	Family     string `json:"fam,omitempty"`
+	MFAPending bool   `json:"mfa_pending,omitempty"`
+	APIKeyID   int    `json:"-"`
 	jwt.RegisteredClaims
 }
 
-func (j *Auth) GenerateTokenPair(user *models.User) (TokenPair, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	claims := token.Claims.(jwt.MapClaims)
+func (j *Auth) GenerateTokenPair(user *models.User, familyID ...string) (TokenPair, error) {
+	claims := jwt.MapClaims{}
 	claims["name"] = fmt.Sprintf("%s %s", user.FirstName, user.LastName)
 	claims["sub"] = fmt.Sprint(user.ID)
 	claims["iss"] = j.Issuer
 	claims["aud"] = j.Audience
+	claims["role"] = user.Role
 	claims["iat"] = time.Now().UTC().Unix()
 	claims["typ"] = "JWT"
 	claims["exp"] = time.Now().UTC().Add(j.TokenExpiry).Unix()
 
-	signedAccessToken, err := token.SignedString([]byte(j.Secret))
+	signedAccessToken, err := j.sign(claims)
+
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refreshTokenID, err := randomTokenID()
 
 	if err != nil {
 		return TokenPair{}, err
 	}
 
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
+	family := refreshTokenID
+
+	if len(familyID) > 0 {
+		family = familyID[0]
+	}
 
-	refreshTokenClaims := refreshToken.Claims.(jwt.MapClaims)
+	refreshExpiresAt := time.Now().UTC().Add(j.RefreshExpiry)
+
+	refreshTokenClaims := jwt.MapClaims{}
 	refreshTokenClaims["sub"] = fmt.Sprint(user.ID)
+	refreshTokenClaims["jti"] = refreshTokenID
+	refreshTokenClaims["fam"] = family
 	refreshTokenClaims["iat"] = time.Now().UTC().Unix()
-	refreshTokenClaims["exp"] = time.Now().UTC().Add(j.RefreshExpiry).Unix()
+	refreshTokenClaims["exp"] = refreshExpiresAt.Unix()
 
-	signedRefreshToken, err := refreshToken.SignedString([]byte(j.Secret))
+	signedRefreshToken, err := j.sign(refreshTokenClaims)
 
 	if err != nil {
 		return TokenPair{}, err
 	}
 
 	var tokenPair = TokenPair{
-		Token:        signedAccessToken,
-		RefreshToken: signedRefreshToken,
+		Token:            signedAccessToken,
+		RefreshToken:     signedRefreshToken,
+		RefreshTokenID:   refreshTokenID,
+		FamilyID:         family,
+		RefreshExpiresAt: refreshExpiresAt,
 	}
 
 	return tokenPair, nil
@@ -119,15 +147,7 @@ func (j *Auth) GetAndVerifyTokenFromHeader(w http.ResponseWriter, r *http.Reques
 
 	claims := &Claims{}
 
-	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (any, error) {
-		_, ok := token.Method.(*jwt.SigningMethodHMAC)
-
-		if !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-
-		return []byte(j.Secret), nil
-	})
+	_, err := jwt.ParseWithClaims(token, claims, j.keyFunc)
 
 	if err != nil {
 		if strings.HasPrefix(err.Error(), "token is expired by") {
@@ -137,9 +157,124 @@ func (j *Auth) GetAndVerifyTokenFromHeader(w http.ResponseWriter, r *http.Reques
 		return "", nil, err
 	}
 
-	if claims.Issuer != j.Issuer {
+	if claims.Issuer != j.Issuer || claims.MFAPending {
 		return "", nil, errors.New("unkown token")
 	}
 
 	return token, claims, nil
 }
+
+func (j *Auth) GenerateMFAToken(user *models.User) (string, error) {
+	claims := jwt.MapClaims{}
+	claims["sub"] = fmt.Sprint(user.ID)
+	claims["mfa_pending"] = true
+	claims["iat"] = time.Now().UTC().Unix()
+	claims["exp"] = time.Now().UTC().Add(j.MFAExpiry).Unix()
+
+	return j.sign(claims)
+}
+
+func (j *Auth) ParseMFAToken(mfaToken string) (*Claims, error) {
+	claims := &Claims{}
+
+	_, err := jwt.ParseWithClaims(mfaToken, claims, j.keyFunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !claims.MFAPending {
+		return nil, errors.New("not a two-factor challenge token")
+	}
+
+	return claims, nil
+}
+
+func (j *Auth) ParseRefreshToken(refreshToken string) (*Claims, error) {
+	claims := &Claims{}
+
+	_, err := jwt.ParseWithClaims(refreshToken, claims, j.keyFunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.ID == "" || claims.Family == "" {
+		return nil, errors.New("refresh token is missing its identifier")
+	}
+
+	return claims, nil
+}
+
+func (j *Auth) JSONWebKeySet() JSONWebKeySet {
+	keySet := JSONWebKeySet{Keys: []JSONWebKey{}}
+
+	for _, key := range j.SigningKeys {
+		jsonWebKey, ok := key.JSONWebKey()
+
+		if ok {
+			keySet.Keys = append(keySet.Keys, jsonWebKey)
+		}
+	}
+
+	return keySet
+}
+
+func (j *Auth) activeKey() *SigningKey {
+	if len(j.SigningKeys) > 0 {
+		return j.SigningKeys[0]
+	}
+
+	return newHMACSigningKey(j.Secret)
+}
+
+func (j *Auth) verificationKeys() []*SigningKey {
+	if len(j.SigningKeys) == 0 {
+		return []*SigningKey{newHMACSigningKey(j.Secret)}
+	}
+
+	if j.AcceptLegacySecret {
+		keys := make([]*SigningKey, 0, len(j.SigningKeys)+1)
+		keys = append(keys, j.SigningKeys...)
+
+		return append(keys, newHMACSigningKey(j.Secret))
+	}
+
+	return j.SigningKeys
+}
+
+func (j *Auth) sign(claims jwt.MapClaims) (string, error) {
+	key := j.activeKey()
+
+	token := jwt.NewWithClaims(key.Method, claims)
+
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+
+	return token.SignedString(key.PrivateKey)
+}
+
+func (j *Auth) keyFunc(token *jwt.Token) (any, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	for _, key := range j.verificationKeys() {
+		if key.ID == keyID && key.Method.Alg() == token.Method.Alg() {
+			return key.PublicKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unexpected signing key: %v %v", token.Header["alg"], token.Header["kid"])
+}
+
+func randomTokenID() (string, error) {
+	bytes := make([]byte, 16)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(bytes), nil
+}
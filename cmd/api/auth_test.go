@@ -0,0 +1,148 @@
+package main
+
+import (
+	"backend/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestAuth() Auth {
+	return Auth{
+		Secret:        "a-test-secret-that-is-at-least-32-characters",
+		Issuer:        "example.com",
+		Audience:      "example.com",
+		TokenExpiry:   time.Minute * 15,
+		MFAExpiry:     time.Minute * 5,
+		RefreshExpiry: time.Hour * 24,
+		CookiePath:    "/",
+		CookieName:    "refresh_token",
+	}
+}
+
+func refreshToken(app *application, token string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodGet, "/refresh", nil)
+	request.AddCookie(&http.Cookie{Name: app.auth.CookieName, Value: token})
+
+	recorder := httptest.NewRecorder()
+	app.RefreshToken(recorder, request)
+
+	return recorder
+}
+
+func refreshCookie(t *testing.T, app *application, recorder *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+
+	for _, cookie := range recorder.Result().Cookies() {
+		if cookie.Name == app.auth.CookieName {
+			return cookie
+		}
+	}
+
+	t.Fatalf("expected a %s cookie in the response", app.auth.CookieName)
+
+	return nil
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	repository := &fakeRepository{}
+	user := repository.addUser(&models.User{FirstName: "Ada", Email: "ada@example.com", Role: models.RoleViewer})
+
+	app := &application{DB: repository, auth: newTestAuth()}
+
+	tokenPair, err := app.auth.GenerateTokenPair(user)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = repository.SaveRefreshToken(refreshTokenRecord(user, tokenPair))
+
+	recorder := refreshToken(app, tokenPair.RefreshToken)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	rotated := refreshCookie(t, app, recorder)
+
+	if rotated.Value == "" || rotated.Value == tokenPair.RefreshToken {
+		t.Fatal("expected a new refresh token in the cookie")
+	}
+
+	oldToken, _ := repository.GetRefreshToken(tokenPair.RefreshTokenID)
+
+	if !oldToken.IsRevoked() || oldToken.ReplacedBy == "" {
+		t.Errorf("expected the used refresh token to be revoked and replaced, got %+v", oldToken)
+	}
+
+	recorder = refreshToken(app, rotated.Value)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected the rotated token to refresh, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	for _, token := range repository.refreshTokens {
+		if token.FamilyID != tokenPair.FamilyID {
+			t.Errorf("expected rotated tokens to stay in family %s, got %s", tokenPair.FamilyID, token.FamilyID)
+		}
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	repository := &fakeRepository{}
+	user := repository.addUser(&models.User{FirstName: "Ada", Email: "ada@example.com", Role: models.RoleViewer})
+
+	app := &application{DB: repository, auth: newTestAuth()}
+
+	tokenPair, err := app.auth.GenerateTokenPair(user)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = repository.SaveRefreshToken(refreshTokenRecord(user, tokenPair))
+
+	otherPair, err := app.auth.GenerateTokenPair(user)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = repository.SaveRefreshToken(refreshTokenRecord(user, otherPair))
+
+	recorder := refreshToken(app, tokenPair.RefreshToken)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", recorder.Code, recorder.Body)
+	}
+
+	rotated := refreshCookie(t, app, recorder)
+
+	recorder = refreshToken(app, tokenPair.RefreshToken)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a reused refresh token to get 401, got %d", recorder.Code)
+	}
+
+	if cookie := refreshCookie(t, app, recorder); cookie.MaxAge >= 0 {
+		t.Errorf("expected the refresh cookie to be cleared, got MaxAge %d", cookie.MaxAge)
+	}
+
+	for _, token := range repository.refreshTokens {
+		if token.FamilyID == tokenPair.FamilyID && !token.IsRevoked() {
+			t.Errorf("expected every token in the family to be revoked, %s is still active", token.JTI)
+		}
+
+		if token.FamilyID == otherPair.FamilyID && token.IsRevoked() {
+			t.Errorf("expected tokens from another session to stay active, %s was revoked", token.JTI)
+		}
+	}
+
+	recorder = refreshToken(app, rotated.Value)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("expected the token issued before the reuse to be rejected, got %d", recorder.Code)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"strconv"
+)
+
+type contextKey string
+
+const claimsContextKey contextKey = "claims"
+
+func contextWithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey, claims)
+}
+
+func claimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey).(*Claims)
+
+	return claims, ok && claims != nil
+}
+
+func userIDFromContext(ctx context.Context) (int, bool) {
+	claims, ok := claimsFromContext(ctx)
+
+	if !ok {
+		return 0, false
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+
+	if err != nil {
+		return 0, false
+	}
+
+	return userID, true
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"net/url"
+	"strings"
+)
+
+const (
+	enrichNone        = "none"
+	enrichFillMissing = "fill_missing"
+	enrichOverwrite   = "overwrite"
+)
+
+var enrichableFields = []string{"image", "description", "runtime", "release_date", "rating", "vote_count", "mpaa_rating", "genres"}
+
+var defaultEnrichFields = []string{"image", "description"}
+
+func readEnrichment(query url.Values) (*dtos.EnrichmentReport, dtos.ValidationErrors) {
+	errs := dtos.ValidationErrors{}
+
+	report := &dtos.EnrichmentReport{
+		Mode:    query.Get("enrich"),
+		Fields:  defaultEnrichFields,
+		Changed: []string{},
+	}
+
+	if report.Mode == "" {
+		report.Mode = enrichNone
+	}
+
+	errs.Check(report.Mode == enrichNone || report.Mode == enrichFillMissing || report.Mode == enrichOverwrite,
+		"enrich", "must be none, fill_missing or overwrite")
+
+	if query.Get("enrich_fields") != "" {
+		report.Fields = nil
+
+		for _, field := range strings.Split(query.Get("enrich_fields"), ",") {
+			field = strings.TrimSpace(field)
+			errs.Check(isEnrichableField(field), "enrich_fields", "must be a comma separated list of "+strings.Join(enrichableFields, ", "))
+			report.Fields = append(report.Fields, field)
+		}
+	}
+
+	return report, errs
+}
+
+func (app *application) enrichFromTMDB(movie *models.Movie, report *dtos.EnrichmentReport) error {
+	return app.enrichFromTMDBWith(movie, report, app.DB.UpsertTMDBGenres)
+}
+
+func (app *application) enrichFromTMDBWith(movie *models.Movie, report *dtos.EnrichmentReport, resolveGenres func(genres []*models.Genre) error) error {
+	if report.Mode == enrichNone {
+		return nil
+	}
+
+	tmdbID := movie.TMDBID
+
+	if tmdbID == 0 {
+		tmdbResponse, err := app.tmdb.SearchMovies(movie.Title, 1)
+
+		if err != nil {
+			return err
+		}
+
+		if len(tmdbResponse.Results) == 0 {
+			return nil
+		}
+
+		tmdbID = tmdbResponse.Results[0].ID
+	}
+
+	movieDetails, err := app.tmdb.GetMovie(tmdbID)
+
+	if err != nil {
+		return err
+	}
+
+	var source models.Movie
+
+	applyTMDBDetails(&source, movieDetails)
+	source.MPAARating = movieDetails.Certification(app.TMDBCountry)
+
+	fields := map[string]bool{}
+
+	for _, field := range report.Fields {
+		fields[field] = true
+	}
+
+	changedBefore := len(report.Changed)
+
+	enrich := func(field string, missing bool, differs bool, apply func()) {
+		if !fields[field] || !differs || (report.Mode == enrichFillMissing && !missing) {
+			return
+		}
+
+		apply()
+		report.Changed = append(report.Changed, field)
+	}
+
+	enrich("image", movie.Image == "", source.Image != "" && source.Image != movie.Image, func() {
+		movie.Image = source.Image
+	})
+
+	enrich("description", movie.Description == "", source.Description != "" && source.Description != movie.Description, func() {
+		movie.Description = source.Description
+	})
+
+	enrich("runtime", movie.Duration == 0, source.Duration != 0 && source.Duration != movie.Duration, func() {
+		movie.Duration = source.Duration
+	})
+
+	enrich("release_date", movie.ReleaseDate.IsZero(), !source.ReleaseDate.IsZero() && !source.ReleaseDate.Equal(movie.ReleaseDate), func() {
+		movie.ReleaseDate = source.ReleaseDate
+	})
+
+	enrich("rating", movie.Rating == 0, source.Rating != 0 && source.Rating != movie.Rating, func() {
+		movie.Rating = source.Rating
+	})
+
+	enrich("vote_count", movie.VoteCount == 0, source.VoteCount != 0 && source.VoteCount != movie.VoteCount, func() {
+		movie.VoteCount = source.VoteCount
+	})
+
+	enrich("mpaa_rating", movie.MPAARating == "", source.MPAARating != "" && source.MPAARating != movie.MPAARating, func() {
+		movie.MPAARating = source.MPAARating
+	})
+
+	if fields["genres"] && len(movieDetails.Genres) > 0 && (report.Mode == enrichOverwrite || len(movie.GenresArray) == 0) {
+		var genres []*models.Genre
+
+		for _, tmdbGenre := range movieDetails.Genres {
+			genres = append(genres, &models.Genre{Name: tmdbGenre.Name, TMDBID: tmdbGenre.ID})
+		}
+
+		err = resolveGenres(genres)
+
+		if err != nil {
+			return err
+		}
+
+		var genreIDs []int
+
+		for _, genre := range genres {
+			genreIDs = append(genreIDs, genre.ID)
+		}
+
+		enrich("genres", true, !sameIDs(genreIDs, movie.GenresArray), func() {
+			movie.Genres = genres
+			movie.GenresArray = genreIDs
+		})
+	}
+
+	if movie.TMDBID == 0 && len(report.Changed) > changedBefore {
+		movie.TMDBID = tmdbID
+		report.Changed = append(report.Changed, "tmdb_id")
+	}
+
+	return nil
+}
+
+func isEnrichableField(field string) bool {
+	for _, enrichableField := range enrichableFields {
+		if field == enrichableField {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sameIDs(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	counts := map[int]int{}
+
+	for _, id := range a {
+		counts[id]++
+	}
+
+	for _, id := range b {
+		if counts[id] == 0 {
+			return false
+		}
+
+		counts[id]--
+	}
+
+	return true
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/tmdb"
+	"backend/internal/tmdb/tmdbtest"
+	"testing"
+)
+
+func TestSameIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []int
+		b    []int
+		want bool
+	}{
+		{name: "both empty", want: true},
+		{name: "same order", a: []int{1, 2}, b: []int{1, 2}, want: true},
+		{name: "different order", a: []int{2, 1}, b: []int{1, 2}, want: true},
+		{name: "different length", a: []int{1}, b: []int{1, 2}},
+		{name: "repeated id", a: []int{1, 2}, b: []int{1, 1}},
+		{name: "repeated id reversed", a: []int{1, 1}, b: []int{1, 2}},
+		{name: "same repeated ids", a: []int{0, 0, 3}, b: []int{0, 3, 0}, want: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := sameIDs(test.a, test.b); got != test.want {
+				t.Errorf("sameIDs(%v, %v) = %v, want %v", test.a, test.b, got, test.want)
+			}
+		})
+	}
+}
+
+func TestEnrichFromTMDBRecordsMatchedID(t *testing.T) {
+	server := tmdbtest.NewServer()
+	defer server.Close()
+
+	server.AddMovie(tmdb.MovieDetails{ID: 348, Title: "Alien", ReleaseDate: "1979-05-25", Runtime: 117, Overview: "In space no one can hear you scream."})
+
+	app := &application{tmdb: server.Client(), TMDBCountry: "US"}
+
+	tests := []struct {
+		name       string
+		movie      models.Movie
+		mode       string
+		wantTMDBID int
+	}{
+		{name: "matched by title", movie: models.Movie{Title: "Alien"}, mode: enrichFillMissing, wantTMDBID: 348},
+		{name: "nothing applied", movie: models.Movie{Title: "Alien", Description: "Set aboard the Nostromo."}, mode: enrichFillMissing},
+		{name: "no match", movie: models.Movie{Title: "Nonexistent"}, mode: enrichFillMissing},
+		{name: "enrichment disabled", movie: models.Movie{Title: "Alien"}, mode: enrichNone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			movie := test.movie
+			report := &dtos.EnrichmentReport{Mode: test.mode, Fields: []string{"description"}, Changed: []string{}}
+
+			err := app.enrichFromTMDBWith(&movie, report, func([]*models.Genre) error { return nil })
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if movie.TMDBID != test.wantTMDBID {
+				t.Errorf("expected tmdb_id %d, got %d (changed %v)", test.wantTMDBID, movie.TMDBID, report.Changed)
+			}
+		})
+	}
+}
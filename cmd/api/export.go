@@ -0,0 +1,336 @@
+package main
+
+import (
+	"backend/internal/models"
+	"backend/internal/xlsx"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exportFormatCSV   = "csv"
+	exportFormatJSONL = "jsonl"
+	exportFormatXLSX  = "xlsx"
+	exportFlushEvery  = 100
+)
+
+var exportContentTypes = map[string]string{
+	exportFormatCSV:   "text/csv; charset=utf-8",
+	exportFormatJSONL: "application/x-ndjson",
+	exportFormatXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+var exportColumns = []string{"id", "title", "year", "release_date", "runtime", "mpaa_rating", "rating", "vote_count", "genres", "tmdb_id", "image", "description"}
+
+type movieExporter interface {
+	WriteMovie(movie *models.Movie) error
+	Flush() error
+	Close() error
+}
+
+type exportedMovie struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Year        int      `json:"year,omitempty"`
+	ReleaseDate string   `json:"release_date,omitempty"`
+	Runtime     int      `json:"runtime"`
+	MPAARating  string   `json:"mpaa_rating"`
+	Rating      float32  `json:"rating"`
+	VoteCount   int      `json:"vote_count"`
+	Genres      []string `json:"genres"`
+	TMDBID      int      `json:"tmdb_id,omitempty"`
+	Image       string   `json:"image,omitempty"`
+	Description string   `json:"description"`
+}
+
+func (app *application) ExportMovies(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(r.URL.Query().Get("format"))
+
+	if format == "" {
+		format = exportFormatCSV
+	}
+
+	contentType, ok := exportContentTypes[format]
+
+	if !ok {
+		app.errorJSON(w, errors.New("format must be csv, jsonl or xlsx"))
+		return
+	}
+
+	movieQuery, err := app.readMovieQuery(r.URL.Query())
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	movieQuery.Limit = 0
+	movieQuery.Offset = 0
+
+	var exporter movieExporter
+
+	startExport := func() error {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="movies-%s.%s"`, time.Now().Format("20060102"), format))
+
+		var err error
+
+		exporter, err = newMovieExporter(w, format)
+
+		return err
+	}
+
+	exported := 0
+
+	err = app.DB.StreamMovies(movieQuery, func(movie *models.Movie) error {
+		if exporter == nil {
+			err := startExport()
+
+			if err != nil {
+				return err
+			}
+		}
+
+		err := exporter.WriteMovie(movie)
+
+		if err != nil {
+			return err
+		}
+
+		exported++
+
+		if exported%exportFlushEvery == 0 {
+			return exporter.Flush()
+		}
+
+		return nil
+	})
+
+	if err != nil && exporter == nil {
+		w.Header().Del("Content-Disposition")
+		app.errorJSON(w, err)
+		return
+	}
+
+	if err != nil {
+		log.Printf("exporting movies after %d rows: %v", exported, err)
+		panic(http.ErrAbortHandler)
+	}
+
+	if exporter == nil {
+		err = startExport()
+
+		if err != nil {
+			log.Println("starting export:", err)
+			panic(http.ErrAbortHandler)
+		}
+	}
+
+	err = exporter.Close()
+
+	if err != nil {
+		log.Println("finishing export:", err)
+	}
+}
+
+func newMovieExporter(w http.ResponseWriter, format string) (movieExporter, error) {
+	switch format {
+	case exportFormatJSONL:
+		return newJSONLExporter(w), nil
+	case exportFormatXLSX:
+		return newXLSXExporter(w)
+	default:
+		return newCSVExporter(w)
+	}
+}
+
+func newExportedMovie(movie *models.Movie) exportedMovie {
+	exported := exportedMovie{
+		ID:          movie.ID,
+		Title:       movie.Title,
+		Runtime:     movie.Duration,
+		MPAARating:  movie.MPAARating,
+		Rating:      movie.Rating,
+		VoteCount:   movie.VoteCount,
+		Genres:      []string{},
+		TMDBID:      movie.TMDBID,
+		Image:       movie.Image,
+		Description: movie.Description,
+	}
+
+	if !movie.ReleaseDate.IsZero() {
+		exported.Year = movie.ReleaseDate.Year()
+		exported.ReleaseDate = movie.ReleaseDate.Format("2006-01-02")
+	}
+
+	for _, genre := range movie.Genres {
+		exported.Genres = append(exported.Genres, genre.Name)
+	}
+
+	return exported
+}
+
+func flushResponse(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type csvExporter struct {
+	w      http.ResponseWriter
+	writer *csv.Writer
+}
+
+func newCSVExporter(w http.ResponseWriter) (movieExporter, error) {
+	exporter := &csvExporter{w: w, writer: csv.NewWriter(w)}
+
+	err := exporter.writer.Write(exportColumns)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return exporter, nil
+}
+
+func (e *csvExporter) WriteMovie(movie *models.Movie) error {
+	exported := newExportedMovie(movie)
+
+	return e.writer.Write([]string{
+		strconv.Itoa(exported.ID),
+		spreadsheetSafe(exported.Title),
+		optionalInt(exported.Year),
+		exported.ReleaseDate,
+		strconv.Itoa(exported.Runtime),
+		spreadsheetSafe(exported.MPAARating),
+		strconv.FormatFloat(float64(exported.Rating), 'f', -1, 32),
+		strconv.Itoa(exported.VoteCount),
+		spreadsheetSafe(strings.Join(exported.Genres, csvGenreSeparator)),
+		optionalInt(exported.TMDBID),
+		spreadsheetSafe(exported.Image),
+		spreadsheetSafe(exported.Description),
+	})
+}
+
+func (e *csvExporter) Flush() error {
+	e.writer.Flush()
+	flushResponse(e.w)
+
+	return e.writer.Error()
+}
+
+func (e *csvExporter) Close() error {
+	return e.Flush()
+}
+
+type jsonlExporter struct {
+	w       http.ResponseWriter
+	buffer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONLExporter(w http.ResponseWriter) *jsonlExporter {
+	buffer := bufio.NewWriter(w)
+
+	return &jsonlExporter{w: w, buffer: buffer, encoder: json.NewEncoder(buffer)}
+}
+
+func (e *jsonlExporter) WriteMovie(movie *models.Movie) error {
+	return e.encoder.Encode(newExportedMovie(movie))
+}
+
+func (e *jsonlExporter) Flush() error {
+	err := e.buffer.Flush()
+	flushResponse(e.w)
+
+	return err
+}
+
+func (e *jsonlExporter) Close() error {
+	return e.Flush()
+}
+
+type xlsxExporter struct {
+	w      http.ResponseWriter
+	writer *xlsx.Writer
+}
+
+func newXLSXExporter(w http.ResponseWriter) (movieExporter, error) {
+	writer, err := xlsx.NewWriter(w, "Movies")
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.WriteHeader(exportColumns...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &xlsxExporter{w: w, writer: writer}, nil
+}
+
+func (e *xlsxExporter) WriteMovie(movie *models.Movie) error {
+	exported := newExportedMovie(movie)
+
+	var year, tmdbID any
+
+	if exported.Year > 0 {
+		year = exported.Year
+	}
+
+	if exported.TMDBID > 0 {
+		tmdbID = exported.TMDBID
+	}
+
+	return e.writer.WriteRow(
+		exported.ID,
+		exported.Title,
+		year,
+		movie.ReleaseDate,
+		exported.Runtime,
+		exported.MPAARating,
+		exported.Rating,
+		exported.VoteCount,
+		strings.Join(exported.Genres, ", "),
+		tmdbID,
+		exported.Image,
+		exported.Description,
+	)
+}
+
+func (e *xlsxExporter) Flush() error {
+	err := e.writer.Flush()
+	flushResponse(e.w)
+
+	return err
+}
+
+func (e *xlsxExporter) Close() error {
+	return e.writer.Close()
+}
+
+func optionalInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(value)
+}
+
+func spreadsheetSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+)
+
+type fakeRepository struct {
+	repositories.Repository
+
+	mu             sync.Mutex
+	users          []*models.User
+	passwordResets []*models.PasswordReset
+	identities     map[string]int
+	refreshTokens  []*models.RefreshToken
+	apiKeys        []*models.APIKey
+	importJobs     []*models.ImportJob
+}
+
+func (f *fakeRepository) addUser(user *models.User) *models.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	user.ID = len(f.users) + 1
+	f.users = append(f.users, user)
+
+	return user
+}
+
+func (f *fakeRepository) GetUserByEmail(email string) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeRepository) GetUserByID(id int) (*models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, user := range f.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	reset.ID = len(f.passwordResets) + 1
+	f.passwordResets = append(f.passwordResets, reset)
+
+	return nil
+}
+
+func (f *fakeRepository) GetUserByIdentity(issuer string, subject string) (*models.User, error) {
+	f.mu.Lock()
+	userID, ok := f.identities[issuer+" "+subject]
+	f.mu.Unlock()
+
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return f.GetUserByID(userID)
+}
+
+func (f *fakeRepository) LinkUserIdentity(userID int, issuer string, subject string, email string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.identities == nil {
+		f.identities = map[string]int{}
+	}
+
+	if _, ok := f.identities[issuer+" "+subject]; !ok {
+		f.identities[issuer+" "+subject] = userID
+	}
+
+	return nil
+}
+
+func (f *fakeRepository) SaveRefreshToken(token *models.RefreshToken) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	token.ID = len(f.refreshTokens) + 1
+	f.refreshTokens = append(f.refreshTokens, token)
+
+	return nil
+}
+
+func (f *fakeRepository) GetRefreshToken(jti string) (*models.RefreshToken, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, token := range f.refreshTokens {
+		if token.JTI == jti {
+			stored := *token
+			return &stored, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeRepository) RotateRefreshToken(oldJTI string, token *models.RefreshToken) error {
+	f.mu.Lock()
+
+	for _, oldToken := range f.refreshTokens {
+		if oldToken.JTI != oldJTI {
+			continue
+		}
+
+		if oldToken.RevokedAt != nil {
+			f.mu.Unlock()
+			return repositories.ErrRefreshTokenRevoked
+		}
+
+		now := time.Now()
+		oldToken.RevokedAt = &now
+		oldToken.ReplacedBy = token.JTI
+	}
+
+	f.mu.Unlock()
+
+	return f.SaveRefreshToken(token)
+}
+
+func (f *fakeRepository) RevokeRefreshTokenFamily(familyID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := time.Now()
+
+	for _, token := range f.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+func (f *fakeRepository) addAPIKey(apiKey *models.APIKey) *models.APIKey {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	apiKey.ID = len(f.apiKeys) + 1
+	f.apiKeys = append(f.apiKeys, apiKey)
+
+	return apiKey
+}
+
+func (f *fakeRepository) GetAPIKeys() ([]*models.APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.apiKeys, nil
+}
+
+func (f *fakeRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, apiKey := range f.apiKeys {
+		if apiKey.KeyHash == keyHash {
+			return apiKey, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeRepository) TouchAPIKey(id int) error {
+	return nil
+}
+
+func (f *fakeRepository) GetImportJob(id int) (*models.ImportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, job := range f.importJobs {
+		if job.ID == id {
+			return job, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
@@ -3,16 +3,15 @@ package main
 import (
 	"backend/internal/dtos"
 	"backend/internal/models"
+	"backend/internal/repositories"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/golang-jwt/jwt/v4"
 )
 
 func (app *application) Home(w http.ResponseWriter, r *http.Request) {
@@ -29,7 +28,20 @@ func (app *application) Home(w http.ResponseWriter, r *http.Request) {
 	_ = app.writeJSON(w, http.StatusOK, payload)
 }
 
+func (app *application) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	_ = app.writeJSON(w, http.StatusOK, app.auth.JSONWebKeySet())
+}
+
 func (app *application) SaveMovie(w http.ResponseWriter, r *http.Request) {
+	enrichment, errs := readEnrichment(r.URL.Query())
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
 	movie, err := app.fromRequestToMovie(w, r)
 
 	if err != nil {
@@ -37,6 +49,17 @@ func (app *application) SaveMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = app.enrichFromTMDB(movie, enrichment)
+
+	if err != nil {
+		log.Println(err)
+		enrichment.Error = tmdbErrorMessage(err)
+	}
+
+	userID, _ := userIDFromContext(r.Context())
+	movie.CreatedBy = userID
+	movie.UpdatedBy = userID
+
 	err = func() error {
 		if movie.ID > 0 {
 			return app.DB.UpdateMovie(movie)
@@ -45,14 +68,32 @@ func (app *application) SaveMovie(w http.ResponseWriter, r *http.Request) {
 		return app.DB.SaveMovie(movie)
 	}()
 
+	if errors.Is(err, repositories.ErrDuplicateTMDBID) {
+		app.errorJSON(w, err, http.StatusConflict)
+		return
+	}
+
 	if err != nil {
 		app.errorJSON(w, err)
 		return
 	}
 
+	err = app.downloadPoster(movie)
+
+	if err != nil {
+		log.Printf("downloading poster for movie %d: %v", movie.ID, err)
+	}
+
+	savedMovie := dtos.SavedMovie{Movie: movie}
+
+	if enrichment.Mode != enrichNone {
+		savedMovie.Enrichment = enrichment
+	}
+
 	response := dtos.JSONResponse{
 		Error:   false,
 		Message: fmt.Sprintf("Movie %d successfuly saved", movie.ID),
+		Data:    savedMovie,
 	}
 
 	_ = app.writeJSON(w, http.StatusCreated, response)
@@ -68,103 +109,68 @@ func (app *application) ImportMovies(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	otherWords := strings.Split(importParams.OtherWords, " ")
-
-	var searchTerms []string
+	errs := importParams.Validate()
 
-	for _, otherWord := range otherWords {
-		searchTerms = append(searchTerms, importParams.PivotWords+" "+otherWord)
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
 	}
 
-	var movies []models.Movie
-	var importedIDs []int
+	importParams.TMDBIDs = nil
 
-	isTheIDAlreadyImported := func(importedList []int, newID int) bool {
-		for _, importedID := range importedIDs {
-			if newID == importedID {
-				return true
-			}
-		}
+	if importParams.DryRun {
+		app.previewImport(w, importParams)
+		return
+	}
+
+	claims, ok := claimsFromContext(r.Context())
 
-		return false
+	if !ok || !models.RoleSatisfies(claims.Role, models.RoleAdmin) {
+		app.errorJSON(w, errors.New("only admins can import without a dry run, confirm the selected TMDB IDs instead"), http.StatusForbidden)
+		return
 	}
 
-	for _, searchTerm := range searchTerms {
-		tmdbResponse, err := app.searchTMDB(searchTerm)
+	app.enqueueImport(w, r, importParams)
+}
 
-		if err != nil {
-			log.Println(err)
-		}
+func (app *application) GetMovies(w http.ResponseWriter, r *http.Request) {
+	movieQuery, err := app.readMovieQuery(r.URL.Query())
 
-		for _, tmdbResult := range tmdbResponse.Results {
-			ratingInt, _ := strconv.Atoi(importParams.Rating)
-			voteCountInt, _ := strconv.Atoi(importParams.Votes)
-			yearInt, _ := strconv.Atoi(importParams.Year)
-
-			ratingIsntEnough := tmdbResult.VoteAverage < float32(ratingInt)
-			voteCountIsntEnough := float32(tmdbResult.VoteCount) < float32(voteCountInt)
-
-			releaseDate, err := time.Parse("2006-01-02", tmdbResult.ReleaseDate)
-
-			if err != nil {
-				fmt.Println(err)
-				continue
-			}
-
-			if yearInt > releaseDate.Year() || ratingIsntEnough || voteCountIsntEnough || tmdbResult.PosterPath == "" {
-				continue
-			}
-
-			if isTheIDAlreadyImported(importedIDs, tmdbResult.ID) {
-				continue
-			}
-
-			moviesInDB, _ := app.DB.GetMovies(dtos.SearchColumn{
-				Column:   "title",
-				Operator: "=",
-				Value:    tmdbResult.Title,
-			})
-
-			if len(moviesInDB) > 0 {
-				continue
-			}
-
-			movie := models.Movie{
-				Title:       tmdbResult.Title,
-				Description: tmdbResult.Overview,
-				ReleaseDate: releaseDate,
-				MPAARating:  "L",
-				Rating:      tmdbResult.VoteAverage,
-				VoteCount:   tmdbResult.VoteCount,
-				Image:       tmdbResult.PosterPath,
-			}
-
-			movies = append(movies, movie)
-			importedIDs = append(importedIDs, tmdbResult.ID)
-		}
+	if err != nil {
+		app.errorJSON(w, err)
+		return
 	}
 
-	for _, movie := range movies {
-		_ = app.DB.SaveMovie(&movie)
-	}
+	app.writeMoviesPage(w, r, movieQuery)
+}
 
-	response := dtos.JSONResponse{
-		Error:   false,
-		Message: fmt.Sprintf("Successfuly imported %d movies", len(movies)),
+func (app *application) SearchMovies(w http.ResponseWriter, r *http.Request) {
+	search := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	if search == "" {
+		app.errorJSON(w, errors.New("search term is required"))
+		return
 	}
 
-	_ = app.writeJSON(w, http.StatusCreated, response)
-}
+	movieQuery, err := app.readMovieQuery(r.URL.Query())
 
-func (app *application) GetMovies(w http.ResponseWriter, r *http.Request) {
-	movies, err := app.DB.GetAllMovies()
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	results, total, err := app.DB.SearchMovies(search, movieQuery)
 
 	if err != nil {
 		app.errorJSON(w, err)
 		return
 	}
 
-	_ = app.writeJSON(w, http.StatusOK, movies)
+	if results == nil {
+		results = []*dtos.MovieSearchResult{}
+	}
+
+	app.writePage(w, r, results, total, movieQuery)
 }
 
 func (app *application) GetMovie(w http.ResponseWriter, r *http.Request) {
@@ -193,6 +199,13 @@ func (app *application) DeleteMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	movie, err := app.DB.GetMovieByID(id)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
 	err = app.DB.DeleteMovie(id)
 
 	if err != nil {
@@ -200,6 +213,10 @@ func (app *application) DeleteMovie(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if movie.PosterHash != "" {
+		app.deletePoster(movie.ID, movie.PosterHash)
+	}
+
 	var payload = dtos.JSONResponse{
 		Error:   false,
 		Message: "Successfuly executed",
@@ -209,14 +226,14 @@ func (app *application) DeleteMovie(w http.ResponseWriter, r *http.Request) {
 }
 
 func (app *application) GetMoviesCatalogue(w http.ResponseWriter, r *http.Request) {
-	movies, err := app.DB.GetAllMovies()
+	movieQuery, err := app.readMovieQuery(r.URL.Query())
 
 	if err != nil {
 		app.errorJSON(w, err)
 		return
 	}
 
-	_ = app.writeJSON(w, http.StatusOK, movies)
+	app.writeMoviesPage(w, r, movieQuery)
 }
 
 func (app *application) GetGenres(w http.ResponseWriter, r *http.Request) {
@@ -230,6 +247,38 @@ func (app *application) GetGenres(w http.ResponseWriter, r *http.Request) {
 	_ = app.writeJSON(w, http.StatusOK, genres)
 }
 
+func (app *application) GetMoviesByGenre(w http.ResponseWriter, r *http.Request) {
+	genreID, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, errors.New("invalid genre id"))
+		return
+	}
+
+	genres, err := app.DB.GetGenres(repositories.NewFilter("id", repositories.Equal, genreID))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if len(genres) == 0 {
+		app.errorJSON(w, errors.New("genre not found"), http.StatusNotFound)
+		return
+	}
+
+	movieQuery, err := app.readMovieQuery(r.URL.Query())
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	movieQuery.GenreID = genreID
+
+	app.writeMoviesPage(w, r, movieQuery)
+}
+
 func (app *application) Authenticate(w http.ResponseWriter, r *http.Request) {
 	var requestPayload struct {
 		Email    string `json:"email"`
@@ -243,9 +292,17 @@ func (app *application) Authenticate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	retryAfter := app.loginRetryAfter(r, requestPayload.Email)
+
+	if retryAfter > 0 {
+		app.tooManyAttemptsJSON(w, retryAfter)
+		return
+	}
+
 	user, err := app.DB.GetUserByEmail(requestPayload.Email)
 
 	if err != nil {
+		app.recordLoginFailure(r, requestPayload.Email)
 		app.errorJSON(w, errors.New("invalid credentials"), http.StatusBadRequest)
 		return
 	}
@@ -253,76 +310,153 @@ func (app *application) Authenticate(w http.ResponseWriter, r *http.Request) {
 	valid, err := user.PasswordMatches(requestPayload.Password)
 
 	if err != nil || !valid {
+		app.recordLoginFailure(r, requestPayload.Email)
 		app.errorJSON(w, errors.New("invalid credentials"), http.StatusBadRequest)
 		return
 	}
 
-	tokenPair, err := app.auth.GenerateTokenPair(user)
+	if user.TOTPEnabled {
+		mfaToken, err := app.auth.GenerateMFAToken(user)
+
+		if err != nil {
+			app.errorJSON(w, err)
+			return
+		}
+
+		var payload = struct {
+			MFARequired bool   `json:"mfa_required"`
+			MFAToken    string `json:"mfa_token"`
+		}{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+
+		_ = app.writeJSON(w, http.StatusOK, payload)
+		return
+	}
+
+	app.recordLoginSuccess(requestPayload.Email)
+
+	app.startSession(w, user)
+}
+
+func (app *application) startSession(w http.ResponseWriter, user *models.User) {
+	tokenPair, err := app.createSession(w, user)
 
 	if err != nil {
 		app.errorJSON(w, err)
+		return
+	}
+
+	app.writeJSON(w, http.StatusOK, tokenPair)
+}
+
+func (app *application) createSession(w http.ResponseWriter, user *models.User) (TokenPair, error) {
+	tokenPair, err := app.auth.GenerateTokenPair(user)
+
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	err = app.DB.SaveRefreshToken(refreshTokenRecord(user, tokenPair))
+
+	if err != nil {
+		return TokenPair{}, err
 	}
 
 	http.SetCookie(w, app.auth.GetRefreshCookie(tokenPair.RefreshToken))
 
-	app.writeJSON(w, http.StatusOK, tokenPair)
+	return tokenPair, nil
 }
 
 func (app *application) RefreshToken(w http.ResponseWriter, r *http.Request) {
-	for _, cookie := range r.Cookies() {
-		if cookie.Name == app.auth.CookieName {
-			claims := &Claims{}
-			refreshToken := cookie.Value
+	cookie, err := r.Cookie(app.auth.CookieName)
 
-			_, err := jwt.ParseWithClaims(refreshToken, claims, func(token *jwt.Token) (any, error) {
-				return []byte(app.JWTSecret), nil
-			})
+	if err != nil {
+		app.errorJSON(w, errors.New("refresh token absent"), http.StatusUnauthorized)
+		return
+	}
 
-			if err != nil {
-				app.errorJSON(w, errors.New("unauthorized"), http.StatusUnauthorized)
-				return
-			}
+	claims, err := app.auth.ParseRefreshToken(cookie.Value)
 
-			userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		app.errorJSON(w, errors.New("unauthorized"), http.StatusUnauthorized)
+		return
+	}
 
-			if err != nil {
-				app.errorJSON(w, errors.New("unkown user"), http.StatusUnauthorized)
-				return
-			}
+	storedToken, err := app.DB.GetRefreshToken(claims.ID)
 
-			user, err := app.DB.GetUserByID(userID)
+	if err != nil {
+		app.errorJSON(w, errors.New("unauthorized"), http.StatusUnauthorized)
+		return
+	}
 
-			if err != nil {
-				app.errorJSON(w, errors.New("unkown user"), http.StatusUnauthorized)
-				return
-			}
+	if storedToken.IsRevoked() {
+		_ = app.DB.RevokeRefreshTokenFamily(storedToken.FamilyID)
+		http.SetCookie(w, app.auth.GetExpiredRefreshCookie())
+		app.errorJSON(w, errors.New("refresh token reused"), http.StatusUnauthorized)
+		return
+	}
 
-			tokenPair, err := app.auth.GenerateTokenPair(user)
+	if storedToken.IsExpired() {
+		app.errorJSON(w, errors.New("unauthorized"), http.StatusUnauthorized)
+		return
+	}
 
-			if err != nil {
-				app.errorJSON(w, errors.New("error generating tokens"), http.StatusUnauthorized)
-				return
-			}
+	user, err := app.DB.GetUserByID(storedToken.UserID)
 
-			http.SetCookie(w, app.auth.GetRefreshCookie(tokenPair.RefreshToken))
+	if err != nil {
+		app.errorJSON(w, errors.New("unkown user"), http.StatusUnauthorized)
+		return
+	}
 
-			app.writeJSON(w, http.StatusOK, tokenPair)
+	tokenPair, err := app.auth.GenerateTokenPair(user, storedToken.FamilyID)
 
-			return
-		}
+	if err != nil {
+		app.errorJSON(w, errors.New("error generating tokens"), http.StatusUnauthorized)
+		return
+	}
+
+	err = app.DB.RotateRefreshToken(storedToken.JTI, refreshTokenRecord(user, tokenPair))
+
+	if errors.Is(err, repositories.ErrRefreshTokenRevoked) {
+		_ = app.DB.RevokeRefreshTokenFamily(storedToken.FamilyID)
+		http.SetCookie(w, app.auth.GetExpiredRefreshCookie())
+		app.errorJSON(w, errors.New("refresh token reused"), http.StatusUnauthorized)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
 	}
 
-	app.errorJSON(w, errors.New("refresh token absent"), http.StatusUnauthorized)
+	http.SetCookie(w, app.auth.GetRefreshCookie(tokenPair.RefreshToken))
+
+	app.writeJSON(w, http.StatusOK, tokenPair)
 }
 
 func (app *application) Logout(w http.ResponseWriter, r *http.Request) {
-	for _, cookie := range r.Cookies() {
-		if cookie.Name == app.auth.CookieName {
-			cookie.MaxAge = -1
-			cookie.Expires = time.Now().Add(-150 * time.Hour)
-			http.SetCookie(w, cookie)
+	cookie, err := r.Cookie(app.auth.CookieName)
+
+	if err == nil {
+		claims, err := app.auth.ParseRefreshToken(cookie.Value)
+
+		if err == nil {
+			_ = app.DB.RevokeRefreshTokenFamily(claims.Family)
 		}
 	}
 
+	http.SetCookie(w, app.auth.GetExpiredRefreshCookie())
+
 	w.WriteHeader(http.StatusAccepted)
 }
+
+func refreshTokenRecord(user *models.User, tokenPair TokenPair) *models.RefreshToken {
+	return &models.RefreshToken{
+		JTI:       tokenPair.RefreshTokenID,
+		FamilyID:  tokenPair.FamilyID,
+		UserID:    user.ID,
+		ExpiresAt: tokenPair.RefreshExpiresAt,
+	}
+}
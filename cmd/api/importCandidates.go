@@ -0,0 +1,273 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/tmdb"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultImportPages = 5
+
+const (
+	reasonInvalidReleaseDate = "invalid_release_date"
+	reasonTooOld             = "too_old"
+	reasonBelowRating        = "below_rating"
+	reasonTooFewVotes        = "too_few_votes"
+	reasonNoPoster           = "no_poster"
+	reasonDuplicate          = "duplicate"
+)
+
+type importCriteria struct {
+	rating     int
+	votes      int
+	year       int
+	maxPages   int
+	maxResults int
+}
+
+func newImportCriteria(importParams dtos.ImportMovies) importCriteria {
+	rating, _ := strconv.Atoi(importParams.Rating)
+	votes, _ := strconv.Atoi(importParams.Votes)
+	year, _ := strconv.Atoi(importParams.Year)
+
+	criteria := importCriteria{
+		rating:     rating,
+		votes:      votes,
+		year:       year,
+		maxPages:   importParams.MaxPages,
+		maxResults: importParams.MaxResults,
+	}
+
+	if criteria.maxPages == 0 {
+		criteria.maxPages = defaultImportPages
+	}
+
+	return criteria
+}
+
+func (c importCriteria) resultsExhausted(seenIDs map[int]bool) bool {
+	return c.maxResults > 0 && len(seenIDs) >= c.maxResults
+}
+
+func importSearchTerms(importParams dtos.ImportMovies) []string {
+	var searchTerms []string
+
+	for _, otherWord := range strings.Split(importParams.OtherWords, " ") {
+		searchTerms = append(searchTerms, importParams.PivotWords+" "+otherWord)
+	}
+
+	return searchTerms
+}
+
+func (app *application) ConfirmImport(w http.ResponseWriter, r *http.Request) {
+	var confirmImport dtos.ConfirmImport
+
+	err := app.readJSON(w, r, &confirmImport)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := confirmImport.Validate()
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	app.enqueueImport(w, r, dtos.ImportMovies{TMDBIDs: confirmImport.TMDBIDs})
+}
+
+func (app *application) enqueueImport(w http.ResponseWriter, r *http.Request, importParams dtos.ImportMovies) {
+	params, err := json.Marshal(importParams)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	userID, _ := userIDFromContext(r.Context())
+
+	job := models.ImportJob{
+		Params:    params,
+		CreatedBy: userID,
+	}
+
+	err = app.DB.CreateImportJob(&job)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	app.notifyImportWorkers()
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("Import job %d queued", job.ID),
+		Data:    job,
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/admin/imports/%d", job.ID))
+
+	_ = app.writeJSON(w, http.StatusAccepted, response)
+}
+
+func (app *application) previewImport(w http.ResponseWriter, importParams dtos.ImportMovies) {
+	criteria := newImportCriteria(importParams)
+	seenIDs := map[int]bool{}
+
+	preview := dtos.ImportPreview{
+		Candidates: []dtos.ImportCandidate{},
+		Filtered:   []dtos.ImportCandidate{},
+	}
+
+	for _, searchTerm := range importSearchTerms(importParams) {
+		candidates, err := app.searchImportCandidates(searchTerm, criteria, seenIDs)
+
+		if errors.Is(err, tmdb.ErrUnauthorized) {
+			log.Println(err)
+			app.errorJSON(w, errors.New(tmdbErrorMessage(err)), http.StatusBadGateway)
+			return
+		}
+
+		if err != nil {
+			log.Println(err)
+			preview.Errors = append(preview.Errors, fmt.Sprintf("searching %q: %s", searchTerm, tmdbErrorMessage(err)))
+		}
+
+		for _, candidate := range candidates {
+			if len(candidate.Reasons) > 0 {
+				preview.Filtered = append(preview.Filtered, candidate)
+			} else {
+				preview.Candidates = append(preview.Candidates, candidate)
+			}
+		}
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("%d movies would be imported", len(preview.Candidates)),
+		Data:    preview,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func (app *application) searchImportCandidates(searchTerm string, criteria importCriteria, seenIDs map[int]bool) ([]dtos.ImportCandidate, error) {
+	var candidates []dtos.ImportCandidate
+
+	for page := 1; page <= criteria.maxPages && !criteria.resultsExhausted(seenIDs); page++ {
+		tmdbResponse, err := app.tmdb.SearchMovies(searchTerm, page)
+
+		if err != nil {
+			return candidates, fmt.Errorf("searching %q page %d: %w", searchTerm, page, err)
+		}
+
+		for _, tmdbResult := range tmdbResponse.Results {
+			if seenIDs[tmdbResult.ID] || criteria.resultsExhausted(seenIDs) {
+				continue
+			}
+
+			seenIDs[tmdbResult.ID] = true
+
+			candidate := newImportCandidate(tmdbResult)
+			candidate.Reasons = criteria.rejectReasons(candidate)
+
+			err = app.checkDuplicateCandidate(&candidate)
+
+			if err != nil {
+				return candidates, err
+			}
+
+			candidates = append(candidates, candidate)
+		}
+
+		if page >= tmdbResponse.TotalPages {
+			break
+		}
+	}
+
+	return candidates, nil
+}
+
+func (app *application) checkDuplicateCandidate(candidate *dtos.ImportCandidate) error {
+	moviesInDB, err := app.DB.GetMovies(repositories.NewFilter("tmdb_id", repositories.Equal, candidate.TMDBID))
+
+	if err != nil {
+		return err
+	}
+
+	if len(moviesInDB) == 0 && candidate.Year > 0 {
+		moviesInDB, err = app.DB.GetMovies(
+			repositories.NewFilter("tmdb_id", repositories.IsNull),
+			repositories.NewFilter("title", repositories.Equal, candidate.Title),
+			repositories.NewFilter("release_date", repositories.Between,
+				fmt.Sprintf("%d-01-01", candidate.Year), fmt.Sprintf("%d-12-31", candidate.Year)),
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(moviesInDB) > 0 {
+		candidate.Reasons = append(candidate.Reasons, reasonDuplicate)
+	}
+
+	return nil
+}
+
+func (c importCriteria) rejectReasons(candidate dtos.ImportCandidate) []string {
+	var reasons []string
+
+	if candidate.Year == 0 {
+		reasons = append(reasons, reasonInvalidReleaseDate)
+	} else if c.year > candidate.Year {
+		reasons = append(reasons, reasonTooOld)
+	}
+
+	if candidate.Rating < float32(c.rating) {
+		reasons = append(reasons, reasonBelowRating)
+	}
+
+	if candidate.Votes < c.votes {
+		reasons = append(reasons, reasonTooFewVotes)
+	}
+
+	if candidate.Poster == "" {
+		reasons = append(reasons, reasonNoPoster)
+	}
+
+	return reasons
+}
+
+func newImportCandidate(tmdbResult tmdb.SearchResult) dtos.ImportCandidate {
+	candidate := dtos.ImportCandidate{
+		TMDBID:      tmdbResult.ID,
+		Title:       tmdbResult.Title,
+		Overview:    tmdbResult.Overview,
+		ReleaseDate: tmdbResult.ReleaseDate,
+		Rating:      tmdbResult.VoteAverage,
+		Votes:       tmdbResult.VoteCount,
+		Poster:      tmdbResult.PosterPath,
+	}
+
+	releaseDate, err := time.Parse("2006-01-02", tmdbResult.ReleaseDate)
+
+	if err == nil {
+		candidate.Year = releaseDate.Year()
+	}
+
+	return candidate
+}
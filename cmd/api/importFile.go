@@ -0,0 +1,553 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxImportFileBytes = 10 << 20
+	maxImportFileRows  = 5000
+	maxEnrichFileRows  = 100
+	csvGenreSeparator  = "|"
+)
+
+const (
+	importFormatCSV   = "csv"
+	importFormatJSONL = "jsonl"
+)
+
+const (
+	importModePerRow      = "per_row"
+	importModeTransaction = "transaction"
+)
+
+var importFileColumns = []string{"title", "year", "runtime", "mpaa_rating", "genres", "description", "tmdb_id"}
+
+var utf8BOM = []byte("\ufeff")
+
+type importFileEntry struct {
+	line   int
+	row    dtos.ImportFileRow
+	reason string
+	errs   dtos.ValidationErrors
+	movie  *models.Movie
+}
+
+func (e *importFileEntry) issue(reason string) dtos.ImportFileIssue {
+	return dtos.ImportFileIssue{Line: e.line, Title: e.row.Title, Reason: reason}
+}
+
+func (app *application) ImportMoviesFile(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	enrichment, errs := readEnrichment(query)
+	mode := query.Get("mode")
+
+	if mode == "" {
+		mode = importModePerRow
+	}
+
+	errs.Check(mode == importModePerRow || mode == importModeTransaction, "mode", "must be per_row or transaction")
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	data, format, err := readImportFile(w, r)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	entries, err := parseImportFile(data, format)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if len(entries) == 0 {
+		app.errorJSON(w, errors.New("file does not contain any movies"))
+		return
+	}
+
+	if len(entries) > maxImportFileRows {
+		app.errorJSON(w, fmt.Errorf("file must not contain more than %d movies", maxImportFileRows), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if enrichment.Mode != enrichNone && len(entries) > maxEnrichFileRows {
+		app.errorJSON(w, fmt.Errorf("files with more than %d movies cannot be enriched from TMDB, import them without enrich and resync afterwards", maxEnrichFileRows), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	genres, err := app.DB.GetGenres()
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	genreIDs := map[string]int{}
+	resolveGenres := app.DB.UpsertTMDBGenres
+
+	for _, genre := range genres {
+		genreIDs[strings.ToLower(genre.Name)] = genre.ID
+	}
+
+	if mode == importModeTransaction {
+		resolveGenres = knownGenresResolver(genres)
+	}
+
+	userID, _ := userIDFromContext(r.Context())
+
+	report := dtos.ImportFileReport{
+		Format:   format,
+		Mode:     mode,
+		Total:    len(entries),
+		MovieIDs: []int{},
+		Skipped:  []dtos.ImportFileIssue{},
+		Failed:   []dtos.ImportFileIssue{},
+	}
+
+	seen := map[string]bool{}
+
+	var pending []*importFileEntry
+
+	for _, entry := range entries {
+		if entry.reason != "" {
+			report.Failed = append(report.Failed, entry.issue(entry.reason))
+			continue
+		}
+
+		for field, message := range entry.row.Validate() {
+			entry.errs.Add(field, message)
+		}
+
+		if len(entry.errs) == 0 {
+			entry.movie = newImportFileMovie(entry.row, genreIDs, entry.errs)
+		}
+
+		if len(entry.errs) > 0 {
+			issue := entry.issue("")
+			issue.Errors = entry.errs
+			report.Failed = append(report.Failed, issue)
+			continue
+		}
+
+		entry.movie.CreatedBy = userID
+		entry.movie.UpdatedBy = userID
+
+		rowEnrichment := &dtos.EnrichmentReport{Mode: enrichment.Mode, Fields: enrichment.Fields, Changed: []string{}}
+
+		err := app.enrichFromTMDBWith(entry.movie, rowEnrichment, resolveGenres)
+
+		if err != nil {
+			log.Printf("enriching import file line %d: %v", entry.line, err)
+			report.Warnings = append(report.Warnings, entry.issue("enriching from TMDB: "+tmdbErrorMessage(err)))
+		}
+
+		duplicate, err := app.importFileDuplicate(entry.movie, seen)
+
+		if err != nil {
+			report.Failed = append(report.Failed, entry.issue(err.Error()))
+			continue
+		}
+
+		if duplicate {
+			report.Skipped = append(report.Skipped, entry.issue(reasonDuplicate))
+			continue
+		}
+
+		if mode == importModeTransaction {
+			pending = append(pending, entry)
+			continue
+		}
+
+		err = app.DB.SaveMovie(entry.movie)
+
+		if errors.Is(err, repositories.ErrDuplicateTMDBID) {
+			report.Skipped = append(report.Skipped, entry.issue(reasonDuplicate))
+			continue
+		}
+
+		if err != nil {
+			report.Failed = append(report.Failed, entry.issue(err.Error()))
+			continue
+		}
+
+		app.importedFromFile(&report, entry)
+	}
+
+	if mode == importModeTransaction && len(report.Failed) == 0 && len(pending) > 0 {
+		movies := make([]*models.Movie, len(pending))
+
+		for i, entry := range pending {
+			movies[i] = entry.movie
+		}
+
+		err = app.DB.SaveMovies(movies)
+
+		var batchError *repositories.BatchError
+
+		if errors.As(err, &batchError) {
+			report.Failed = append(report.Failed, pending[batchError.Index].issue(batchError.Err.Error()))
+		} else if err != nil {
+			app.errorJSON(w, err)
+			return
+		} else {
+			for _, entry := range pending {
+				app.importedFromFile(&report, entry)
+			}
+		}
+	}
+
+	if mode == importModeTransaction && len(report.Failed) > 0 {
+		response := dtos.JSONResponse{
+			Error:   true,
+			Message: fmt.Sprintf("No movies imported, %d rows failed", len(report.Failed)),
+			Data:    report,
+		}
+
+		_ = app.writeJSON(w, http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: fmt.Sprintf("%d movies successfuly imported", report.Imported),
+		Data:    report,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func (app *application) importedFromFile(report *dtos.ImportFileReport, entry *importFileEntry) {
+	report.Imported++
+	report.MovieIDs = append(report.MovieIDs, entry.movie.ID)
+
+	err := app.downloadPoster(entry.movie)
+
+	if err != nil {
+		report.Warnings = append(report.Warnings, entry.issue("downloading poster: "+err.Error()))
+	}
+}
+
+func knownGenresResolver(genres []*models.Genre) func(genres []*models.Genre) error {
+	genreIDs := map[int]int{}
+
+	for _, genre := range genres {
+		if genre.TMDBID > 0 {
+			genreIDs[genre.TMDBID] = genre.ID
+		}
+	}
+
+	return func(tmdbGenres []*models.Genre) error {
+		for _, genre := range tmdbGenres {
+			genre.ID = genreIDs[genre.TMDBID]
+		}
+
+		return nil
+	}
+}
+
+func (app *application) importFileDuplicate(movie *models.Movie, seen map[string]bool) (bool, error) {
+	candidate := dtos.ImportCandidate{
+		TMDBID: movie.TMDBID,
+		Title:  movie.Title,
+		Year:   movie.ReleaseDate.Year(),
+	}
+
+	tmdbKey := fmt.Sprintf("tmdb:%d", candidate.TMDBID)
+	titleKey := fmt.Sprintf("title:%d:%s", candidate.Year, candidate.Title)
+
+	if (candidate.TMDBID > 0 && seen[tmdbKey]) || seen[titleKey] {
+		return true, nil
+	}
+
+	err := app.checkDuplicateCandidate(&candidate)
+
+	if err != nil {
+		return false, err
+	}
+
+	if len(candidate.Reasons) > 0 {
+		return true, nil
+	}
+
+	if candidate.TMDBID > 0 {
+		seen[tmdbKey] = true
+	}
+
+	seen[titleKey] = true
+
+	return false, nil
+}
+
+func newImportFileMovie(row dtos.ImportFileRow, genreIDs map[string]int, errs dtos.ValidationErrors) *models.Movie {
+	movie := &models.Movie{
+		Title:       row.Title,
+		Duration:    row.Runtime,
+		MPAARating:  row.MPAARating,
+		Description: row.Description,
+		ReleaseDate: time.Date(row.Year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		TMDBID:      row.TMDBID,
+	}
+
+	for _, name := range row.Genres {
+		genreID, ok := genreIDs[strings.ToLower(strings.TrimSpace(name))]
+
+		if !ok {
+			errs.Add("genres", fmt.Sprintf("unknown genre %q", name))
+			continue
+		}
+
+		movie.GenresArray = append(movie.GenresArray, genreID)
+	}
+
+	return movie
+}
+
+func readImportFile(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes+1<<20)
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var data []byte
+
+	if contentType == "multipart/form-data" {
+		file, header, err := r.FormFile("file")
+
+		if err != nil {
+			return nil, "", fmt.Errorf("file is required: %w", err)
+		}
+
+		defer file.Close()
+
+		contentType, _, _ = mime.ParseMediaType(header.Header.Get("Content-Type"))
+
+		if format == "" {
+			format = importFormatFromName(header.Filename)
+		}
+
+		data, err = io.ReadAll(io.LimitReader(file, maxImportFileBytes+1))
+
+		if err != nil {
+			return nil, "", err
+		}
+	} else {
+		var err error
+
+		data, err = io.ReadAll(io.LimitReader(r.Body, maxImportFileBytes+1))
+
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if len(data) > maxImportFileBytes {
+		return nil, "", errors.New("file must not be larger than 10MB")
+	}
+
+	if format == "" {
+		format = importFormatFromContentType(contentType)
+	}
+
+	if format != importFormatCSV && format != importFormatJSONL {
+		return nil, "", errors.New("format must be csv or jsonl")
+	}
+
+	return bytes.TrimPrefix(data, utf8BOM), format, nil
+}
+
+func importFormatFromName(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return importFormatCSV
+	case ".jsonl", ".ndjson":
+		return importFormatJSONL
+	}
+
+	return ""
+}
+
+func importFormatFromContentType(contentType string) string {
+	switch contentType {
+	case "text/csv":
+		return importFormatCSV
+	case "application/jsonl", "application/x-jsonlines", "application/x-ndjson":
+		return importFormatJSONL
+	}
+
+	return ""
+}
+
+func parseImportFile(data []byte, format string) ([]*importFileEntry, error) {
+	if format == importFormatCSV {
+		return parseImportCSV(data)
+	}
+
+	return parseImportJSONL(data), nil
+}
+
+func parseImportCSV(data []byte) ([]*importFileEntry, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+
+	if err == io.EOF {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	columns := map[string]int{}
+
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if !isImportFileColumn(name) {
+			return nil, fmt.Errorf("unknown column %q, expected %s", name, strings.Join(importFileColumns, ", "))
+		}
+
+		columns[name] = i
+	}
+
+	if _, ok := columns["title"]; !ok {
+		return nil, errors.New("title column is required")
+	}
+
+	var entries []*importFileEntry
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if err != nil {
+			entry := &importFileEntry{line: line, reason: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))}
+
+			if columns["title"] < len(record) {
+				entry.row.Title = strings.TrimSpace(record[columns["title"]])
+			}
+
+			entries = append(entries, entry)
+			continue
+		}
+
+		entries = append(entries, newCSVImportEntry(line, record, columns))
+	}
+
+	return entries, nil
+}
+
+func newCSVImportEntry(line int, record []string, columns map[string]int) *importFileEntry {
+	entry := &importFileEntry{line: line, errs: dtos.ValidationErrors{}}
+
+	field := func(name string) string {
+		i, ok := columns[name]
+
+		if !ok {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	number := func(name string) int {
+		value := field(name)
+
+		if value == "" {
+			return 0
+		}
+
+		n, err := strconv.Atoi(value)
+		entry.errs.Check(err == nil, name, "must be a whole number")
+
+		return n
+	}
+
+	entry.row = dtos.ImportFileRow{
+		Title:       field("title"),
+		Year:        number("year"),
+		Runtime:     number("runtime"),
+		MPAARating:  field("mpaa_rating"),
+		Description: field("description"),
+		TMDBID:      number("tmdb_id"),
+	}
+
+	if genres := field("genres"); genres != "" {
+		entry.row.Genres = strings.Split(genres, csvGenreSeparator)
+	}
+
+	return entry
+}
+
+func parseImportJSONL(data []byte) []*importFileEntry {
+	var entries []*importFileEntry
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+
+		if len(line) == 0 {
+			continue
+		}
+
+		entry := &importFileEntry{line: i + 1, errs: dtos.ValidationErrors{}}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+
+		err := decoder.Decode(&entry.row)
+
+		if err == nil && decoder.More() {
+			err = errors.New("line must contain a single JSON object")
+		}
+
+		if err != nil {
+			entry.reason = err.Error()
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func isImportFileColumn(name string) bool {
+	for _, column := range importFileColumns {
+		if name == column {
+			return true
+		}
+	}
+
+	return false
+}
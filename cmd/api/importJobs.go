@@ -0,0 +1,391 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/tmdb"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	importJobPollInterval = 5 * time.Second
+	importJobLease        = 30 * time.Second
+	importJobHeartbeat    = 5 * time.Second
+	maxImportJobAttempts  = 3
+	defaultMPAARating     = "L"
+)
+
+var errImportCancelled = errors.New("import job cancelled")
+
+func (app *application) GetImportJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	job, err := app.DB.GetImportJob(id)
+
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !canManageImportJob(r, job)) {
+		app.errorJSON(w, errors.New("import job not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, job)
+}
+
+func (app *application) CancelImportJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	job, err := app.DB.GetImportJob(id)
+
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !canManageImportJob(r, job)) {
+		app.errorJSON(w, errors.New("import job not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	cancelled, err := app.DB.CancelImportJob(id)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	job, err = app.DB.GetImportJob(id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		app.errorJSON(w, errors.New("import job not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if !cancelled {
+		app.errorJSON(w, fmt.Errorf("import job is already %s", job.Status), http.StatusConflict)
+		return
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "Import job successfuly cancelled",
+		Data:    job,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func canManageImportJob(r *http.Request, job *models.ImportJob) bool {
+	claims, ok := claimsFromContext(r.Context())
+
+	if !ok {
+		return false
+	}
+
+	if models.RoleSatisfies(claims.Role, models.RoleAdmin) {
+		return true
+	}
+
+	userID, _ := userIDFromContext(r.Context())
+
+	return userID > 0 && job.CreatedBy == userID
+}
+
+func (app *application) startImportWorkers(workers int) {
+	app.importWake = make(chan struct{}, workers)
+
+	for i := 0; i < workers; i++ {
+		go app.importWorker()
+	}
+}
+
+func (app *application) notifyImportWorkers() {
+	select {
+	case app.importWake <- struct{}{}:
+	default:
+	}
+}
+
+func (app *application) importWorker() {
+	ticker := time.NewTicker(importJobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := app.DB.ClaimImportJob(importJobLease, maxImportJobAttempts)
+
+		if err == nil {
+			app.runImportJob(job)
+			continue
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println("claiming import job:", err)
+		}
+
+		select {
+		case <-app.importWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (app *application) runImportJob(job *models.ImportJob) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go app.keepImportJobLease(ctx, cancel, job)
+
+	var importParams dtos.ImportMovies
+
+	job.Status = models.ImportJobCompleted
+
+	err := json.Unmarshal(job.Params, &importParams)
+
+	if err != nil {
+		job.Status = models.ImportJobFailed
+		job.Error = "invalid import parameters: " + err.Error()
+	} else {
+		err = app.importMovies(ctx, job, importParams)
+
+		if errors.Is(err, errImportCancelled) {
+			return
+		}
+
+		if err != nil {
+			log.Printf("import job %d failed: %v", job.ID, err)
+			job.Status = models.ImportJobFailed
+			job.Error = tmdbErrorMessage(err)
+		}
+	}
+
+	err = app.DB.FinishImportJob(job)
+
+	if err != nil {
+		log.Printf("finishing import job %d: %v", job.ID, err)
+	}
+}
+
+func (app *application) keepImportJobLease(ctx context.Context, cancel context.CancelFunc, job *models.ImportJob) {
+	ticker := time.NewTicker(importJobHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewed, err := app.DB.RenewImportJobLease(job, importJobLease)
+
+		if err != nil {
+			log.Printf("renewing import job %d lease: %v", job.ID, err)
+			continue
+		}
+
+		if !renewed {
+			cancel()
+			return
+		}
+	}
+}
+
+func (app *application) importMovies(ctx context.Context, job *models.ImportJob, importParams dtos.ImportMovies) error {
+	if len(importParams.TMDBIDs) > 0 {
+		return app.importSelectedMovies(ctx, job, importParams.TMDBIDs)
+	}
+
+	criteria := newImportCriteria(importParams)
+	seenIDs := map[int]bool{}
+	resumeAfter := job.Considered
+	position := 0
+
+	for _, searchTerm := range importSearchTerms(importParams) {
+		if ctx.Err() != nil {
+			return errImportCancelled
+		}
+
+		candidates, err := app.searchImportCandidates(searchTerm, criteria, seenIDs)
+
+		if errors.Is(err, tmdb.ErrUnauthorized) {
+			return err
+		}
+
+		if err != nil {
+			log.Printf("import job %d: %v", job.ID, err)
+			app.addImportJobError(job, models.ImportJobError{Reason: fmt.Sprintf("searching %q: %s", searchTerm, tmdbErrorMessage(err))})
+		}
+
+		for _, candidate := range candidates {
+			if ctx.Err() != nil {
+				return errImportCancelled
+			}
+
+			position++
+
+			if position <= resumeAfter {
+				continue
+			}
+
+			app.importCandidate(job, candidate)
+			app.saveImportJobProgress(job)
+		}
+	}
+
+	return nil
+}
+
+func (app *application) importSelectedMovies(ctx context.Context, job *models.ImportJob, tmdbIDs []int) error {
+	tmdbIDs = tmdbIDs[min(job.Considered, len(tmdbIDs)):]
+
+	for _, tmdbID := range tmdbIDs {
+		if ctx.Err() != nil {
+			return errImportCancelled
+		}
+
+		movieDetails, err := app.tmdb.GetMovie(tmdbID)
+
+		if errors.Is(err, tmdb.ErrUnauthorized) {
+			return err
+		}
+
+		if err != nil {
+			job.Considered++
+			job.Failed++
+			log.Printf("import job %d: fetching TMDB movie %d: %v", job.ID, tmdbID, err)
+			app.addImportJobError(job, models.ImportJobError{TMDBID: tmdbID, Reason: tmdbErrorMessage(err)})
+			app.saveImportJobProgress(job)
+			continue
+		}
+
+		candidate := newImportCandidate(tmdb.SearchResult{
+			ID:          movieDetails.ID,
+			Title:       movieDetails.Title,
+			ReleaseDate: movieDetails.ReleaseDate,
+			VoteCount:   movieDetails.VoteCount,
+			VoteAverage: movieDetails.VoteAverage,
+			Overview:    movieDetails.Overview,
+			PosterPath:  movieDetails.PosterPath,
+		})
+
+		if candidate.Year == 0 {
+			candidate.Reasons = append(candidate.Reasons, reasonInvalidReleaseDate)
+		}
+
+		err = app.checkDuplicateCandidate(&candidate)
+
+		if err == nil {
+			app.importCandidate(job, candidate)
+		} else {
+			job.Considered++
+			job.Failed++
+			app.addImportJobError(job, models.ImportJobError{TMDBID: tmdbID, Title: candidate.Title, Reason: err.Error()})
+		}
+
+		app.saveImportJobProgress(job)
+	}
+
+	return nil
+}
+
+func (app *application) saveImportJobProgress(job *models.ImportJob) {
+	err := app.DB.UpdateImportJobProgress(job)
+
+	if err != nil {
+		log.Printf("updating import job %d: %v", job.ID, err)
+	}
+}
+
+func (app *application) importCandidate(job *models.ImportJob, candidate dtos.ImportCandidate) {
+	job.Considered++
+
+	if len(candidate.Reasons) > 0 {
+		job.Skipped++
+		return
+	}
+
+	releaseDate, _ := time.Parse("2006-01-02", candidate.ReleaseDate)
+
+	movie := models.Movie{
+		Title:       candidate.Title,
+		Description: candidate.Overview,
+		ReleaseDate: releaseDate,
+		Rating:      candidate.Rating,
+		VoteCount:   candidate.Votes,
+		Image:       candidate.Poster,
+		CreatedBy:   job.CreatedBy,
+		UpdatedBy:   job.CreatedBy,
+		TMDBID:      candidate.TMDBID,
+	}
+
+	err := app.enrichMovie(&movie)
+
+	if err != nil {
+		log.Printf("import job %d: enriching TMDB movie %d: %v", job.ID, candidate.TMDBID, err)
+		app.addImportJobError(job, models.ImportJobError{TMDBID: candidate.TMDBID, Title: candidate.Title, Reason: "enriching from TMDB details, imported with defaults: " + tmdbErrorMessage(err)})
+	}
+
+	if movie.MPAARating == "" {
+		movie.MPAARating = defaultMPAARating
+	}
+
+	err = app.DB.SaveMovie(&movie)
+
+	if errors.Is(err, repositories.ErrDuplicateTMDBID) {
+		job.Skipped++
+		return
+	}
+
+	if err != nil {
+		job.Failed++
+		app.addImportJobError(job, models.ImportJobError{TMDBID: candidate.TMDBID, Title: candidate.Title, Reason: err.Error()})
+		return
+	}
+
+	err = app.downloadPoster(&movie)
+
+	if err != nil {
+		app.addImportJobError(job, models.ImportJobError{TMDBID: candidate.TMDBID, Title: candidate.Title, Reason: "downloading poster: " + err.Error()})
+	}
+
+	job.Imported++
+}
+
+func (app *application) addImportJobError(job *models.ImportJob, jobError models.ImportJobError) {
+	jobError.Attempt = job.Attempt
+
+	err := app.DB.AddImportJobError(job.ID, jobError)
+
+	if err != nil {
+		log.Printf("recording import job %d error: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey any
+	PublicKey  any
+}
+
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	Modulus   string `json:"n,omitempty"`
+	Exponent  string `json:"e,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+func (k *SigningKey) CanSign() bool {
+	return k.PrivateKey != nil
+}
+
+func (k *SigningKey) JSONWebKey() (JSONWebKey, bool) {
+	switch publicKey := k.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return JSONWebKey{
+			KeyType:   "RSA",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Method.Alg(),
+			Modulus:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JSONWebKey{
+			KeyType:   "OKP",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.Method.Alg(),
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(publicKey),
+		}, true
+	}
+
+	return JSONWebKey{}, false
+}
+
+func newHMACSigningKey(secret string) *SigningKey {
+	return &SigningKey{
+		Method:     jwt.SigningMethodHS256,
+		PrivateKey: []byte(secret),
+		PublicKey:  []byte(secret),
+	}
+}
+
+func loadSigningKeys(paths []string) ([]*SigningKey, error) {
+	var keys []*SigningKey
+
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+
+		if path == "" {
+			continue
+		}
+
+		key, err := loadSigningKey(path)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) > 0 && !keys[0].CanSign() {
+		return nil, errors.New("the first signing key must be a private key")
+	}
+
+	return keys, nil
+}
+
+func loadSigningKey(path string) (*SigningKey, error) {
+	contents, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(contents)
+
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var privateKey any
+	var publicKey any
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if signer, ok := privateKey.(crypto.Signer); ok {
+		publicKey = signer.Public()
+	}
+
+	var method jwt.SigningMethod
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey:
+		method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", publicKey)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	thumbprint := sha256.Sum256(der)
+
+	return &SigningKey{
+		ID:         base64.RawURLEncoding.EncodeToString(thumbprint[:12]),
+		Method:     method,
+		PrivateKey: privateKey,
+		PublicKey:  publicKey,
+	}, nil
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/throttle"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type loginLimiters struct {
+	IP      *throttle.Limiter
+	Account *throttle.Limiter
+}
+
+func newLoginLimiters(store throttle.Store) loginLimiters {
+	return loginLimiters{
+		IP: &throttle.Limiter{
+			Store: store,
+			Policy: throttle.Policy{
+				MaxFailures:     50,
+				BaseDelay:       time.Second,
+				MaxDelay:        time.Minute,
+				LockoutDuration: time.Hour,
+				ResetAfter:      time.Hour,
+			},
+		},
+		Account: &throttle.Limiter{
+			Store: store,
+			Policy: throttle.Policy{
+				MaxFailures:     5,
+				BaseDelay:       time.Second,
+				MaxDelay:        5 * time.Minute,
+				LockoutDuration: 15 * time.Minute,
+				ResetAfter:      24 * time.Hour,
+			},
+		},
+	}
+}
+
+func newPasswordResetLimiters(store throttle.Store) loginLimiters {
+	return loginLimiters{
+		IP: &throttle.Limiter{
+			Store: store,
+			Policy: throttle.Policy{
+				MaxFailures:     20,
+				LockoutDuration: time.Hour,
+				ResetAfter:      time.Hour,
+			},
+		},
+		Account: &throttle.Limiter{
+			Store: store,
+			Policy: throttle.Policy{
+				MaxFailures:     3,
+				LockoutDuration: time.Hour,
+				ResetAfter:      time.Hour,
+			},
+		},
+	}
+}
+
+func (app *application) loginRetryAfter(r *http.Request, email string) time.Duration {
+	ipWait, err := app.loginLimiters.IP.RetryAfter(ipKey(r))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	accountWait, err := app.loginLimiters.Account.RetryAfter(accountKey(email))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return max(ipWait, accountWait)
+}
+
+func (app *application) recordLoginFailure(r *http.Request, email string) {
+	err := app.loginLimiters.IP.Fail(ipKey(r))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	err = app.loginLimiters.Account.Fail(accountKey(email))
+
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (app *application) recordLoginSuccess(email string) {
+	err := app.loginLimiters.Account.Reset(accountKey(email))
+
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (app *application) tooManyAttemptsJSON(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+
+	app.errorJSON(w, errors.New("too many login attempts, try again later"), http.StatusTooManyRequests)
+}
+
+func (app *application) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	user, err := app.DB.GetUserByID(id)
+
+	if err != nil {
+		app.errorJSON(w, errors.New("unkown user"), http.StatusNotFound)
+		return
+	}
+
+	err = app.loginLimiters.Account.Reset(accountKey(user.Email))
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "Account successfuly unlocked",
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
@@ -1,41 +1,164 @@
 package main
 
 import (
+	"backend/internal/blobstore"
+	"backend/internal/mailer"
+	"backend/internal/oidc"
 	"backend/internal/repositories"
 	"backend/internal/repositories/postgres"
+	"backend/internal/secretbox"
+	"backend/internal/throttle"
+	"backend/internal/tmdb"
+	"encoding/base64"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
 const port = 8080
 
+const minJWTSecretLength = 32
+
 type application struct {
-	Domain       string
-	DSN          string
-	DB           repositories.Repository
-	auth         Auth
-	JWTSecret    string
-	JWTIssuer    string
-	JWTAudience  string
-	CookieDomain string
-	TMDBAPIKey   string
+	Domain            string
+	DSN               string
+	DB                repositories.Repository
+	auth              Auth
+	JWTSecret         string
+	JWTKeys           string
+	JWTAcceptHS       bool
+	JWTIssuer         string
+	JWTAudience       string
+	CookieDomain      string
+	TMDBAPIKey        string
+	TMDBBaseURL       string
+	TMDBTimeout       time.Duration
+	TMDBRateLimit     float64
+	TMDBCountry       string
+	TMDBRetries       int
+	TMDBCache         string
+	TMDBCacheTTL      time.Duration
+	tmdb              tmdb.Client
+	ImportWorkers     int
+	PosterStore       string
+	PosterDir         string
+	S3                blobstore.S3Store
+	posters           blobstore.Store
+	posterClient      *http.Client
+	importWake        chan struct{}
+	Mailer            mailer.Mailer
+	SMTP              mailer.SMTPMailer
+	PasswordResetURL  string
+	LoginStore        string
+	EncryptionKey     string
+	TOTPEnabled       bool
+	TOTPIssuer        string
+	OIDC              oidc.Config
+	OIDCPostLoginURL  string
+	OIDCAutoProvision bool
+	OIDCLinkByEmail   bool
+	oidc              *oidc.Provider
+	secrets           *secretbox.Box
+	loginLimiters     loginLimiters
+	resetLimiters     loginLimiters
+	background        sync.WaitGroup
 }
 
 func main() {
 	var app application
 
 	flag.StringVar(&app.DSN, "dsn", "host=localhost port=5432 user=postgres password=postgres dbname=movies timezone=UTC connect_timeout=5", "Postgress connection string")
-	flag.StringVar(&app.JWTSecret, "jwt-secret", "bigandrandomsecret", "JWT secret")
+	flag.StringVar(&app.JWTSecret, "jwt-secret", "", "Secret of at least 32 characters used for HS256 tokens when no signing keys are set")
+	flag.StringVar(&app.JWTKeys, "jwt-signing-keys", "", "Comma separated PEM key files (RSA or Ed25519), the first one signs new tokens")
+	flag.BoolVar(&app.JWTAcceptHS, "jwt-accept-secret", false, "Keep accepting HS256 tokens signed with the JWT secret when signing keys are set")
 	flag.StringVar(&app.JWTIssuer, "jwt-issuer", "example.com", "JWT issuer")
 	flag.StringVar(&app.JWTAudience, "jwt-audience", "example.com", "JWT audience")
 	flag.StringVar(&app.CookieDomain, "cookie-domain", "localhost", "Cookie domain")
 	flag.StringVar(&app.Domain, "domain", "example.com", "Application domain")
-	flag.StringVar(&app.TMDBAPIKey, "tmdb-api-key", "9a27a3af220c836a5b5323277c9e53e6", "API key for The Movies DB")
+	flag.StringVar(&app.TMDBAPIKey, "tmdb-api-key", "", "API key for The Movies DB")
+	flag.StringVar(&app.TMDBBaseURL, "tmdb-base-url", tmdb.DefaultBaseURL, "Base URL of The Movies DB API")
+	flag.DurationVar(&app.TMDBTimeout, "tmdb-timeout", tmdb.DefaultTimeout, "Timeout for requests to The Movies DB")
+	flag.Float64Var(&app.TMDBRateLimit, "tmdb-rate-limit", 20, "Maximum requests per second sent to The Movies DB")
+	flag.StringVar(&app.TMDBCountry, "tmdb-certification-country", "US", "Country whose age certification is stored as the MPAA rating of imported movies")
+	flag.IntVar(&app.TMDBRetries, "tmdb-retries", 3, "Retries for rate limited or failed requests to The Movies DB")
+	flag.StringVar(&app.TMDBCache, "tmdb-cache", "memory", "Where The Movies DB responses are cached: memory, postgres or none")
+	flag.DurationVar(&app.TMDBCacheTTL, "tmdb-cache-ttl", 24*time.Hour, "How long The Movies DB responses are cached")
+	flag.IntVar(&app.ImportWorkers, "import-workers", 2, "Number of background workers processing import jobs")
+	flag.StringVar(&app.PosterStore, "poster-store", "fs", "Where posters are stored: fs or s3")
+	flag.StringVar(&app.PosterDir, "poster-dir", "./data/posters", "Directory used by the fs poster store")
+	flag.StringVar(&app.S3.Endpoint, "s3-endpoint", "https://s3.amazonaws.com", "S3 compatible endpoint for the s3 poster store")
+	flag.StringVar(&app.S3.Bucket, "s3-bucket", "", "Bucket for the s3 poster store")
+	flag.StringVar(&app.S3.Region, "s3-region", "us-east-1", "Region for the s3 poster store")
+	flag.StringVar(&app.S3.AccessKey, "s3-access-key", "", "Access key for the s3 poster store")
+	flag.StringVar(&app.S3.SecretKey, "s3-secret-key", "", "Secret key for the s3 poster store")
+	flag.BoolVar(&app.S3.PathStyle, "s3-path-style", true, "Address the bucket in the path instead of the host name")
+	flag.StringVar(&app.PasswordResetURL, "password-reset-url", "http://localhost:3000/reset-password", "Frontend page that receives password reset tokens")
+	flag.StringVar(&app.SMTP.Host, "smtp-host", "", "SMTP host, mails are only logged when empty")
+	flag.IntVar(&app.SMTP.Port, "smtp-port", 587, "SMTP port")
+	flag.StringVar(&app.SMTP.Username, "smtp-username", "", "SMTP username")
+	flag.StringVar(&app.SMTP.Password, "smtp-password", "", "SMTP password")
+	flag.StringVar(&app.SMTP.From, "smtp-from", "Go Movies <no-reply@example.com>", "Sender address for outgoing mail")
+	flag.StringVar(&app.LoginStore, "login-attempts-store", "memory", "Where failed logins are tracked: memory or postgres")
+	flag.StringVar(&app.EncryptionKey, "encryption-key", "", "Base64 encoded 32 byte key used to encrypt secrets at rest")
+	flag.BoolVar(&app.TOTPEnabled, "totp", false, "Allow users to enroll in two-factor authentication, requires encryption-key")
+	flag.StringVar(&app.TOTPIssuer, "totp-issuer", "Go Movies", "Issuer shown by authenticator apps")
+	flag.StringVar(&app.OIDC.IssuerURL, "oidc-issuer", "", "OpenID Connect issuer URL used for discovery, single sign-on is disabled when empty")
+	flag.StringVar(&app.OIDC.ClientID, "oidc-client-id", "", "OpenID Connect client ID")
+	flag.StringVar(&app.OIDC.ClientSecret, "oidc-client-secret", "", "OpenID Connect client secret")
+	flag.StringVar(&app.OIDC.RedirectURL, "oidc-redirect-url", "http://localhost:8080/auth/oidc/callback", "OpenID Connect callback URL registered with the provider")
+	flag.StringVar(&app.OIDCPostLoginURL, "oidc-post-login-url", "", "Frontend page to redirect to after single sign-on, tokens are returned as JSON when empty")
+	flag.BoolVar(&app.OIDCAutoProvision, "oidc-auto-provision", false, "Create a viewer account for unknown single sign-on users")
+	flag.BoolVar(&app.OIDCLinkByEmail, "oidc-link-by-email", false, "Link single sign-on identities to existing accounts with the same verified email")
 	flag.Parse()
 
+	signingKeys, err := loadSigningKeys(strings.Split(app.JWTKeys, ","))
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if (len(signingKeys) == 0 || app.JWTAcceptHS) && len(app.JWTSecret) < minJWTSecretLength {
+		log.Fatalf("jwt-signing-keys or a jwt-secret of at least %d characters is required", minJWTSecretLength)
+	}
+
+	if len(signingKeys) == 0 {
+		log.Println("No JWT signing keys configured, signing tokens with HS256 and the JWT secret")
+	}
+
+	if app.EncryptionKey == "" && (app.TOTPEnabled || app.OIDC.IssuerURL != "") {
+		log.Fatal("encryption-key is required when two-factor authentication or single sign-on is enabled")
+	}
+
+	if app.EncryptionKey != "" {
+		encryptionKey, err := base64.StdEncoding.DecodeString(app.EncryptionKey)
+
+		if err != nil || len(encryptionKey) != 32 {
+			log.Fatal("encryption-key must be 32 bytes encoded as base64")
+		}
+
+		app.secrets = &secretbox.Box{Key: encryptionKey}
+	}
+
+	if app.OIDC.IssuerURL != "" {
+		app.oidc = oidc.NewProvider(app.OIDC)
+	}
+
+	app.Mailer = &mailer.LogMailer{}
+
+	if app.SMTP.Host != "" {
+		err := app.SMTP.Validate()
+
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		app.Mailer = &app.SMTP
+	}
+
 	conn, err := app.connectToDB()
 
 	if err != nil {
@@ -46,15 +169,67 @@ func main() {
 
 	defer app.DB.GetConnection().Close()
 
+	var attemptStore throttle.Store
+
+	switch app.LoginStore {
+	case "memory":
+		attemptStore = &throttle.MemoryStore{TTL: 24 * time.Hour}
+	case "postgres":
+		attemptStore = &postgres.LoginAttemptStore{DB: conn}
+	default:
+		log.Fatalf("unknown login attempts store %q", app.LoginStore)
+	}
+
+	app.loginLimiters = newLoginLimiters(attemptStore)
+	app.resetLimiters = newPasswordResetLimiters(attemptStore)
+
+	if app.TMDBAPIKey == "" {
+		log.Println("tmdb-api-key is not set, imports and enrichment from The Movies DB will fail")
+	}
+
+	app.tmdb = &tmdb.RetryingClient{
+		Client:     tmdb.NewClient(app.TMDBBaseURL, app.TMDBAPIKey, app.TMDBTimeout),
+		Limiter:    tmdb.NewRateLimiter(app.TMDBRateLimit, int(app.TMDBRateLimit)),
+		MaxRetries: app.TMDBRetries,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   10 * time.Second,
+	}
+
+	switch app.TMDBCache {
+	case "memory":
+		app.tmdb = &tmdb.CachedClient{Client: app.tmdb, Cache: &tmdb.MemoryCache{}, TTL: app.TMDBCacheTTL}
+	case "postgres":
+		app.tmdb = &tmdb.CachedClient{Client: app.tmdb, Cache: &postgres.TMDBCache{DB: conn}, TTL: app.TMDBCacheTTL}
+	case "none":
+	default:
+		log.Fatalf("unknown tmdb cache %q", app.TMDBCache)
+	}
+
+	switch app.PosterStore {
+	case "fs":
+		app.posters = &blobstore.FileStore{Root: app.PosterDir}
+	case "s3":
+		app.posters = &app.S3
+	default:
+		log.Fatalf("unknown poster store %q", app.PosterStore)
+	}
+
+	app.posterClient = &http.Client{Timeout: 30 * time.Second}
+
+	app.startImportWorkers(app.ImportWorkers)
+
 	app.auth = Auth{
-		Secret:        app.JWTSecret,
-		Issuer:        app.JWTIssuer,
-		Audience:      app.JWTAudience,
-		TokenExpiry:   time.Minute * 15,
-		RefreshExpiry: time.Hour * 24,
-		CookiePath:    "/",
-		CookieName:    "refresh_token",
-		CookieDomain:  app.CookieDomain,
+		Secret:             app.JWTSecret,
+		SigningKeys:        signingKeys,
+		AcceptLegacySecret: app.JWTAcceptHS,
+		Issuer:             app.JWTIssuer,
+		Audience:           app.JWTAudience,
+		TokenExpiry:        time.Minute * 15,
+		MFAExpiry:          time.Minute * 5,
+		RefreshExpiry:      time.Hour * 24,
+		CookiePath:         "/",
+		CookieName:         "refresh_token",
+		CookieDomain:       app.CookieDomain,
 	}
 
 	log.Println("Starting application on port ", port)
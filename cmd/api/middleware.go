@@ -1,6 +1,9 @@
 package main
 
-import "net/http"
+import (
+	"backend/internal/models"
+	"net/http"
+)
 
 func (app *application) enableCORS(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -9,7 +12,7 @@ func (app *application) enableCORS(h http.Handler) http.Handler {
 
 		if r.Method == "OPTIONS" {
 			w.Header().Set("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, X-CSRF-Token, Authorization")
+			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, X-CSRF-Token, Authorization, X-API-Key")
 
 			return
 		} else {
@@ -20,13 +23,54 @@ func (app *application) enableCORS(h http.Handler) http.Handler {
 
 func (app *application) authRequired(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, _, err := app.auth.GetAndVerifyTokenFromHeader(w, r)
+		var claims *Claims
+		var err error
+
+		if key := apiKeyFromRequest(r); key != "" {
+			w.Header().Add("Vary", "X-API-Key")
+			claims, err = app.authenticateAPIKey(key)
+		} else {
+			_, claims, err = app.auth.GetAndVerifyTokenFromHeader(w, r)
+		}
 
 		if err != nil {
 			w.WriteHeader(http.StatusUnauthorized)
 			return
 		}
 
+		next.ServeHTTP(w, r.WithContext(contextWithClaims(r.Context(), claims)))
+	})
+}
+
+func (app *application) requireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := claimsFromContext(r.Context())
+
+			if !ok {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			if !models.RoleSatisfies(claims.Role, role) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (app *application) denyAPIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := claimsFromContext(r.Context())
+
+		if !ok || claims.APIKeyID != 0 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/tmdb"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (app *application) ResyncMovie(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	var requestPayload struct {
+		TMDBID int `json:"tmdb_id"`
+	}
+
+	if r.ContentLength > 0 {
+		err = app.readJSON(w, r, &requestPayload)
+
+		if err != nil {
+			app.errorJSON(w, err)
+			return
+		}
+	}
+
+	movie, err := app.DB.GetMovieByID(id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		app.errorJSON(w, errors.New("movie not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if requestPayload.TMDBID > 0 {
+		movie.TMDBID = requestPayload.TMDBID
+	}
+
+	if movie.TMDBID == 0 {
+		app.validationErrorJSON(w, dtos.ValidationErrors{"tmdb_id": "must be provided for movies without a TMDB ID"})
+		return
+	}
+
+	if invalidator, ok := app.tmdb.(tmdb.MovieInvalidator); ok {
+		err = invalidator.InvalidateMovie(movie.TMDBID)
+
+		if err != nil {
+			log.Printf("invalidating cached TMDB movie %d: %v", movie.TMDBID, err)
+		}
+	}
+
+	err = app.enrichMovie(movie)
+
+	if errors.Is(err, tmdb.ErrNotFound) {
+		app.errorJSON(w, errors.New("movie not found on TMDB"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		log.Printf("resyncing movie %d from TMDB: %v", movie.ID, err)
+		app.errorJSON(w, errors.New(tmdbErrorMessage(err)), http.StatusBadGateway)
+		return
+	}
+
+	movie.UpdatedBy, _ = userIDFromContext(r.Context())
+
+	err = app.DB.UpdateMovie(movie)
+
+	if errors.Is(err, repositories.ErrDuplicateTMDBID) {
+		app.errorJSON(w, err, http.StatusConflict)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	err = app.downloadPoster(movie)
+
+	if err != nil {
+		log.Printf("downloading poster for movie %d: %v", movie.ID, err)
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "Movie successfuly resynced",
+		Data:    movie,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func tmdbErrorMessage(err error) string {
+	var apiError *tmdb.APIError
+	var urlError *url.Error
+
+	switch {
+	case errors.Is(err, tmdb.ErrUnauthorized):
+		return "TMDB rejected the configured API key"
+	case errors.Is(err, tmdb.ErrNotFound):
+		return "not found on TMDB"
+	case errors.Is(err, tmdb.ErrRateLimited):
+		return "TMDB rate limit exceeded"
+	case errors.As(err, &apiError):
+		return fmt.Sprintf("TMDB returned status %d", apiError.StatusCode)
+	case errors.As(err, &urlError):
+		return "TMDB could not be reached"
+	default:
+		return "request to TMDB failed"
+	}
+}
+
+func (app *application) enrichMovie(movie *models.Movie) error {
+	movieDetails, err := app.tmdb.GetMovie(movie.TMDBID)
+
+	if err != nil {
+		return err
+	}
+
+	applyTMDBDetails(movie, movieDetails)
+
+	certification := movieDetails.Certification(app.TMDBCountry)
+
+	if certification != "" {
+		movie.MPAARating = certification
+	}
+
+	var genres []*models.Genre
+
+	for _, tmdbGenre := range movieDetails.Genres {
+		genres = append(genres, &models.Genre{Name: tmdbGenre.Name, TMDBID: tmdbGenre.ID})
+	}
+
+	err = app.DB.UpsertTMDBGenres(genres)
+
+	if err != nil {
+		return err
+	}
+
+	movie.GenresArray = []int{}
+
+	for _, genre := range genres {
+		movie.GenresArray = append(movie.GenresArray, genre.ID)
+	}
+
+	return nil
+}
+
+func applyTMDBDetails(movie *models.Movie, movieDetails *tmdb.MovieDetails) {
+	movie.TMDBID = movieDetails.ID
+	movie.Title = movieDetails.Title
+	movie.Description = movieDetails.Overview
+	movie.Rating = movieDetails.VoteAverage
+	movie.VoteCount = movieDetails.VoteCount
+
+	releaseDate, err := time.Parse("2006-01-02", movieDetails.ReleaseDate)
+
+	if err == nil {
+		movie.ReleaseDate = releaseDate
+	}
+
+	if movieDetails.Runtime > 0 {
+		movie.Duration = movieDetails.Runtime
+	}
+
+	if movieDetails.PosterPath != "" {
+		movie.Image = movieDetails.PosterPath
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStateExpiry = 10 * time.Minute
+)
+
+type oidcState struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	LinkUserID   int       `json:"link_user_id,omitempty"`
+}
+
+func (app *application) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	if app.oidc == nil {
+		app.errorJSON(w, errors.New("single sign-on is not configured"), http.StatusNotFound)
+		return
+	}
+
+	authURL, ok := app.startOIDCFlow(w, 0)
+
+	if !ok {
+		return
+	}
+
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+func (app *application) LinkOIDCIdentity(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	authURL, ok := app.startOIDCFlow(w, user.ID)
+
+	if !ok {
+		return
+	}
+
+	var payload = struct {
+		URL string `json:"url"`
+	}{
+		URL: authURL,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, payload)
+}
+
+func (app *application) startOIDCFlow(w http.ResponseWriter, linkUserID int) (string, bool) {
+	state := oidcState{LinkUserID: linkUserID}
+
+	var err error
+
+	for _, value := range []*string{&state.State, &state.Nonce, &state.CodeVerifier} {
+		*value, err = oidc.RandomString()
+
+		if err != nil {
+			app.errorJSON(w, err, http.StatusInternalServerError)
+			return "", false
+		}
+	}
+
+	state.ExpiresAt = time.Now().Add(oidcStateExpiry)
+
+	authURL, err := app.oidc.AuthCodeURL(state.State, state.Nonce, state.CodeVerifier)
+
+	if err != nil {
+		log.Println("oidc discovery failed:", err)
+		app.errorJSON(w, errors.New("identity provider unavailable"), http.StatusBadGateway)
+		return "", false
+	}
+
+	stateJSON, err := json.Marshal(state)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return "", false
+	}
+
+	sealedState, err := app.secrets.Seal(string(stateJSON))
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return "", false
+	}
+
+	http.SetCookie(w, app.oidcStateCookie(sealedState, int(oidcStateExpiry.Seconds())))
+
+	return authURL, true
+}
+
+func (app *application) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	if app.oidc == nil {
+		app.errorJSON(w, errors.New("single sign-on is not configured"), http.StatusNotFound)
+		return
+	}
+
+	state, err := app.readOIDCState(r)
+
+	http.SetCookie(w, app.oidcStateCookie("", -1))
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+
+	if query.Get("error") != "" {
+		app.errorJSON(w, errors.New("identity provider returned "+query.Get("error")), http.StatusUnauthorized)
+		return
+	}
+
+	if query.Get("state") != state.State || query.Get("code") == "" {
+		app.errorJSON(w, errors.New("invalid login state"), http.StatusUnauthorized)
+		return
+	}
+
+	tokenResponse, err := app.oidc.Exchange(query.Get("code"), state.CodeVerifier)
+
+	if err != nil {
+		log.Println("oidc code exchange failed:", err)
+		app.errorJSON(w, errors.New("unauthorized"), http.StatusUnauthorized)
+		return
+	}
+
+	claims, err := app.oidc.VerifyIDToken(tokenResponse.IDToken, state.Nonce)
+
+	if err != nil {
+		log.Println("oidc id token rejected:", err)
+		app.errorJSON(w, errors.New("unauthorized"), http.StatusUnauthorized)
+		return
+	}
+
+	var user *models.User
+
+	if state.LinkUserID > 0 {
+		user, err = app.linkIdentity(state.LinkUserID, claims)
+	} else {
+		user, err = app.userForIdentity(claims)
+	}
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusForbidden)
+		return
+	}
+
+	if user.TOTPEnabled {
+		mfaToken, err := app.auth.GenerateMFAToken(user)
+
+		if err != nil {
+			app.errorJSON(w, err)
+			return
+		}
+
+		if app.OIDCPostLoginURL != "" {
+			http.Redirect(w, r, app.OIDCPostLoginURL+"#mfa_token="+url.QueryEscape(mfaToken), http.StatusFound)
+			return
+		}
+
+		var payload = struct {
+			MFARequired bool   `json:"mfa_required"`
+			MFAToken    string `json:"mfa_token"`
+		}{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}
+
+		_ = app.writeJSON(w, http.StatusOK, payload)
+		return
+	}
+
+	if app.OIDCPostLoginURL == "" {
+		app.startSession(w, user)
+		return
+	}
+
+	_, err = app.createSession(w, user)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	http.Redirect(w, r, app.OIDCPostLoginURL, http.StatusFound)
+}
+
+func (app *application) userForIdentity(claims *oidc.IDTokenClaims) (*models.User, error) {
+	user, err := app.DB.GetUserByIdentity(claims.Issuer, claims.Subject)
+
+	if err == nil {
+		return user, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, errors.New("identity provider did not supply a verified email")
+	}
+
+	user, err = app.DB.GetUserByEmail(claims.Email)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		if !app.OIDCAutoProvision {
+			return nil, errors.New("no account is linked to this identity")
+		}
+
+		user, err = app.provisionUser(claims)
+	} else if err == nil && !app.OIDCLinkByEmail {
+		return nil, errors.New("an account with this email already exists and is not linked to this identity")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.DB.LinkUserIdentity(user.ID, claims.Issuer, claims.Subject, claims.Email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (app *application) linkIdentity(userID int, claims *oidc.IDTokenClaims) (*models.User, error) {
+	linkedUser, err := app.DB.GetUserByIdentity(claims.Issuer, claims.Subject)
+
+	if err == nil {
+		if linkedUser.ID != userID {
+			return nil, errors.New("this identity is already linked to another account")
+		}
+
+		return linkedUser, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	user, err := app.DB.GetUserByID(userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.DB.LinkUserIdentity(user.ID, claims.Issuer, claims.Subject, claims.Email)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (app *application) provisionUser(claims *oidc.IDTokenClaims) (*models.User, error) {
+	firstName, lastName := claims.GivenName, claims.FamilyName
+
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(claims.Name, " ")
+	}
+
+	user := models.User{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     claims.Email,
+		Role:      models.RoleViewer,
+	}
+
+	unusablePassword, err := oidc.RandomString()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = user.SetPassword(unusablePassword)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.DB.CreateUser(&user)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (app *application) readOIDCState(r *http.Request) (*oidcState, error) {
+	cookie, err := r.Cookie(oidcStateCookie)
+
+	if err != nil {
+		return nil, errors.New("login state absent")
+	}
+
+	stateJSON, err := app.secrets.Open(cookie.Value)
+
+	if err != nil {
+		return nil, errors.New("invalid login state")
+	}
+
+	var state oidcState
+
+	err = json.Unmarshal([]byte(stateJSON), &state)
+
+	if err != nil || time.Now().After(state.ExpiresAt) {
+		return nil, errors.New("invalid login state")
+	}
+
+	return &state, nil
+}
+
+func (app *application) oidcStateCookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     oidcStateCookie,
+		Path:     "/auth/oidc",
+		Value:    value,
+		MaxAge:   maxAge,
+		SameSite: http.SameSiteLaxMode,
+		Domain:   app.auth.CookieDomain,
+		HttpOnly: true,
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"backend/internal/models"
+	"backend/internal/oidc"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestLinkIdentity(t *testing.T) {
+	claims := &oidc.IDTokenClaims{
+		Email:            "sso@example.com",
+		RegisteredClaims: jwt.RegisteredClaims{Issuer: "https://idp.example.com", Subject: "subject-1"},
+	}
+
+	tests := []struct {
+		name       string
+		linkedTo   int
+		userID     int
+		wantErr    bool
+		wantLinked int
+	}{
+		{
+			name:       "links an unlinked identity",
+			userID:     1,
+			wantLinked: 1,
+		},
+		{
+			name:       "accepts an identity already linked to the same user",
+			linkedTo:   1,
+			userID:     1,
+			wantLinked: 1,
+		},
+		{
+			name:       "rejects an identity linked to another user",
+			linkedTo:   2,
+			userID:     1,
+			wantErr:    true,
+			wantLinked: 2,
+		},
+		{
+			name:    "rejects an unknown user",
+			userID:  3,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &fakeRepository{}
+			repository.addUser(&models.User{Email: "admin@example.com", Role: models.RoleAdmin})
+			repository.addUser(&models.User{Email: "other@example.com", Role: models.RoleViewer})
+
+			if test.linkedTo > 0 {
+				_ = repository.LinkUserIdentity(test.linkedTo, claims.Issuer, claims.Subject, claims.Email)
+			}
+
+			app := &application{DB: repository}
+
+			user, err := app.linkIdentity(test.userID, claims)
+
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if user.ID != test.userID {
+					t.Errorf("expected user %d, got %d", test.userID, user.ID)
+				}
+			}
+
+			linkedUser, err := repository.GetUserByIdentity(claims.Issuer, claims.Subject)
+
+			if test.wantLinked == 0 {
+				if err == nil {
+					t.Errorf("expected the identity to stay unlinked, linked to user %d", linkedUser.ID)
+				}
+
+				return
+			}
+
+			if err != nil || linkedUser.ID != test.wantLinked {
+				t.Errorf("expected the identity to be linked to user %d, got %+v (%v)", test.wantLinked, linkedUser, err)
+			}
+		})
+	}
+}
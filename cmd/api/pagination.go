@@ -0,0 +1,150 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+func (app *application) readMovieQuery(values url.Values) (repositories.MovieQuery, error) {
+	var movieQuery repositories.MovieQuery
+
+	page, err := readIntParam(values, "page", 1)
+
+	if err != nil || page < 1 {
+		return movieQuery, errors.New("invalid page")
+	}
+
+	perPage, err := readIntParam(values, "per_page", defaultPerPage)
+
+	if err != nil || perPage < 1 || perPage > maxPerPage {
+		return movieQuery, fmt.Errorf("per_page must be between 1 and %d", maxPerPage)
+	}
+
+	movieQuery.Limit = perPage
+	movieQuery.Offset = (page - 1) * perPage
+	movieQuery.SortBy = values.Get("sort")
+
+	switch strings.ToLower(values.Get("order")) {
+	case "", "asc":
+	case "desc":
+		movieQuery.Descending = true
+	default:
+		return movieQuery, errors.New("order must be asc or desc")
+	}
+
+	if values.Has("genre_id") {
+		movieQuery.GenreID, err = strconv.Atoi(values.Get("genre_id"))
+
+		if err != nil {
+			return movieQuery, errors.New("invalid genre id")
+		}
+	}
+
+	if values.Has("year_from") {
+		yearFrom, err := strconv.Atoi(values.Get("year_from"))
+
+		if err != nil {
+			return movieQuery, errors.New("invalid year_from")
+		}
+
+		movieQuery.Filters = append(movieQuery.Filters, repositories.NewFilter("release_date", repositories.GreaterOrEqual, time.Date(yearFrom, time.January, 1, 0, 0, 0, 0, time.UTC)))
+	}
+
+	if values.Has("year_to") {
+		yearTo, err := strconv.Atoi(values.Get("year_to"))
+
+		if err != nil {
+			return movieQuery, errors.New("invalid year_to")
+		}
+
+		movieQuery.Filters = append(movieQuery.Filters, repositories.NewFilter("release_date", repositories.LessOrEqual, time.Date(yearTo, time.December, 31, 0, 0, 0, 0, time.UTC)))
+	}
+
+	if values.Has("min_rating") {
+		minRating, err := strconv.ParseFloat(values.Get("min_rating"), 32)
+
+		if err != nil {
+			return movieQuery, errors.New("invalid min_rating")
+		}
+
+		movieQuery.Filters = append(movieQuery.Filters, repositories.NewFilter("rating", repositories.GreaterOrEqual, minRating))
+	}
+
+	if values.Get("mpaa_rating") != "" {
+		var mpaaRatings []any
+
+		for _, mpaaRating := range strings.Split(values.Get("mpaa_rating"), ",") {
+			mpaaRatings = append(mpaaRatings, strings.TrimSpace(mpaaRating))
+		}
+
+		movieQuery.Filters = append(movieQuery.Filters, repositories.NewFilter("mpaa_rating", repositories.In, mpaaRatings...))
+	}
+
+	return movieQuery, nil
+}
+
+func (app *application) writeMoviesPage(w http.ResponseWriter, r *http.Request, movieQuery repositories.MovieQuery) {
+	movies, total, err := app.DB.ListMovies(movieQuery)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if movies == nil {
+		movies = []*models.Movie{}
+	}
+
+	app.writePage(w, r, movies, total, movieQuery)
+}
+
+func (app *application) writePage(w http.ResponseWriter, r *http.Request, data any, total int, movieQuery repositories.MovieQuery) {
+	page := movieQuery.Offset/movieQuery.Limit + 1
+	totalPages := (total + movieQuery.Limit - 1) / movieQuery.Limit
+
+	response := dtos.Page{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PerPage:    movieQuery.Limit,
+		TotalPages: totalPages,
+	}
+
+	if page < totalPages {
+		response.Next = pageLink(r, page+1)
+	}
+
+	if page > 1 {
+		response.Prev = pageLink(r, min(page-1, max(totalPages, 1)))
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func readIntParam(values url.Values, key string, defaultValue int) (int, error) {
+	if !values.Has(key) {
+		return defaultValue, nil
+	}
+
+	return strconv.Atoi(values.Get(key))
+}
+
+func pageLink(r *http.Request, page int) string {
+	values := r.URL.Query()
+	values.Set("page", strconv.Itoa(page))
+
+	return r.URL.Path + "?" + values.Encode()
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const passwordResetExpiry = time.Hour
+
+func (app *application) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var forgotPassword dtos.ForgotPassword
+
+	err := app.readJSON(w, r, &forgotPassword)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := forgotPassword.Validate()
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	retryAfter := app.passwordResetRetryAfter(r, forgotPassword.Email)
+
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		app.errorJSON(w, errors.New("too many password reset requests, try again later"), http.StatusTooManyRequests)
+		return
+	}
+
+	app.recordPasswordResetRequest(r, forgotPassword.Email)
+
+	app.runInBackground(func() {
+		app.sendPasswordReset(forgotPassword.Email)
+	})
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "If the email is registered, a reset link is on its way",
+	}
+
+	_ = app.writeJSON(w, http.StatusAccepted, response)
+}
+
+func (app *application) sendPasswordReset(email string) {
+	user, err := app.DB.GetUserByEmail(email)
+
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			log.Println(err)
+		}
+
+		return
+	}
+
+	token, err := generateResetToken()
+
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	reset := models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(passwordResetExpiry),
+	}
+
+	err = app.DB.CreatePasswordReset(&reset)
+
+	if err != nil {
+		log.Println(err)
+		return
+	}
+
+	err = app.Mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your Go Movies password",
+		Body: fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can be used once.\n\n%s\n\nIf you did not ask for a reset, you can ignore this email.\n",
+			user.FirstName, passwordResetExpiry, app.passwordResetLink(token)),
+	})
+
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (app *application) passwordResetRetryAfter(r *http.Request, email string) time.Duration {
+	ipWait, err := app.resetLimiters.IP.RetryAfter("reset:" + ipKey(r))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	accountWait, err := app.resetLimiters.Account.RetryAfter("reset:" + accountKey(email))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	return max(ipWait, accountWait)
+}
+
+func (app *application) recordPasswordResetRequest(r *http.Request, email string) {
+	err := app.resetLimiters.IP.Fail("reset:" + ipKey(r))
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	err = app.resetLimiters.Account.Fail("reset:" + accountKey(email))
+
+	if err != nil {
+		log.Println(err)
+	}
+}
+
+func (app *application) runInBackground(fn func()) {
+	app.background.Add(1)
+
+	go func() {
+		defer app.background.Done()
+
+		defer func() {
+			if err := recover(); err != nil {
+				log.Println("background task panicked:", err)
+			}
+		}()
+
+		fn()
+	}()
+}
+
+func (app *application) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var resetPassword dtos.ResetPassword
+
+	err := app.readJSON(w, r, &resetPassword)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	errs := resetPassword.Validate()
+
+	if len(errs) > 0 {
+		app.validationErrorJSON(w, errs)
+		return
+	}
+
+	var user models.User
+
+	err = user.SetPassword(resetPassword.Password)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := app.DB.ConsumePasswordReset(hashToken(resetPassword.Token), user.Password)
+
+	if errors.Is(err, repositories.ErrInvalidResetToken) {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.DB.RevokeUserRefreshTokens(userID)
+
+	if err != nil {
+		log.Println(err)
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "Password successfuly reset",
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func (app *application) passwordResetLink(token string) string {
+	return app.PasswordResetURL + "?token=" + url.QueryEscape(token)
+}
+
+func generateResetToken() (string, error) {
+	bytes := make([]byte, 32)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
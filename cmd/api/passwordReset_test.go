@@ -0,0 +1,120 @@
+package main
+
+import (
+	"backend/internal/mailer"
+	"backend/internal/models"
+	"backend/internal/throttle"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newPasswordResetApp() (*application, *fakeRepository, *mailer.MemoryMailer) {
+	repository := &fakeRepository{}
+	repository.addUser(&models.User{FirstName: "Ada", Email: "ada@example.com", Role: models.RoleViewer})
+
+	memoryMailer := &mailer.MemoryMailer{}
+	store := &throttle.MemoryStore{TTL: time.Hour}
+
+	app := &application{
+		DB:               repository,
+		Mailer:           memoryMailer,
+		PasswordResetURL: "http://localhost:3000/reset-password",
+		loginLimiters:    newLoginLimiters(store),
+		resetLimiters:    newPasswordResetLimiters(store),
+	}
+
+	return app, repository, memoryMailer
+}
+
+func forgotPassword(app *application, email string, remoteAddr string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(http.MethodPost, "/password/forgot", strings.NewReader(`{"email":"`+email+`"}`))
+	request.RemoteAddr = remoteAddr
+
+	recorder := httptest.NewRecorder()
+	app.ForgotPassword(recorder, request)
+	app.background.Wait()
+
+	return recorder
+}
+
+func TestForgotPassword(t *testing.T) {
+	app, repository, memoryMailer := newPasswordResetApp()
+
+	known := forgotPassword(app, "ada@example.com", "192.0.2.1:1234")
+	unknown := forgotPassword(app, "nobody@example.com", "192.0.2.1:1234")
+
+	if known.Code != http.StatusAccepted || unknown.Code != http.StatusAccepted {
+		t.Fatalf("expected 202 for both emails, got %d and %d", known.Code, unknown.Code)
+	}
+
+	if known.Body.String() != unknown.Body.String() {
+		t.Errorf("responses differ for known and unknown emails:\n%s\n%s", known.Body, unknown.Body)
+	}
+
+	messages := memoryMailer.Messages()
+
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 mail, got %d", len(messages))
+	}
+
+	if messages[0].To != "ada@example.com" || !strings.Contains(messages[0].Body, app.PasswordResetURL+"?token=") {
+		t.Errorf("unexpected mail %+v", messages[0])
+	}
+
+	if len(repository.passwordResets) != 1 || repository.passwordResets[0].UserID != 1 {
+		t.Errorf("expected one reset for user 1, got %+v", repository.passwordResets)
+	}
+}
+
+func TestForgotPasswordThrottlesPerEmail(t *testing.T) {
+	app, _, memoryMailer := newPasswordResetApp()
+
+	for i := 0; i < 3; i++ {
+		recorder := forgotPassword(app, "ada@example.com", "192.0.2.1:1234")
+
+		if recorder.Code != http.StatusAccepted {
+			t.Fatalf("request %d: expected 202, got %d", i+1, recorder.Code)
+		}
+	}
+
+	recorder := forgotPassword(app, "ada@example.com", "198.51.100.7:1234")
+
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 from another IP once the email is throttled, got %d", recorder.Code)
+	}
+
+	if recorder.Header().Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+
+	if len(memoryMailer.Messages()) != 3 {
+		t.Errorf("expected 3 mails, got %d", len(memoryMailer.Messages()))
+	}
+
+	wait, err := app.loginLimiters.Account.RetryAfter(accountKey("ada@example.com"))
+
+	if err != nil || wait != 0 {
+		t.Errorf("reset requests must not count as failed logins, got wait %s, err %v", wait, err)
+	}
+}
+
+func TestForgotPasswordThrottlesPerIP(t *testing.T) {
+	app, _, _ := newPasswordResetApp()
+
+	for i := 0; i < 20; i++ {
+		recorder := forgotPassword(app, "user"+strings.Repeat("x", i)+"@example.com", "192.0.2.1:1234")
+
+		if recorder.Code != http.StatusAccepted {
+			t.Fatalf("request %d: expected 202, got %d", i+1, recorder.Code)
+		}
+	}
+
+	recorder := forgotPassword(app, "another@example.com", "192.0.2.1:1234")
+
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 once the IP is throttled, got %d", recorder.Code)
+	}
+}
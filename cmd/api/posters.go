@@ -0,0 +1,302 @@
+package main
+
+import (
+	"backend/internal/blobstore"
+	"backend/internal/dtos"
+	"backend/internal/imaging"
+	"backend/internal/models"
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	maxPosterBytes     = 10 << 20
+	posterQuality      = 85
+	posterSourceUpload = "upload"
+	posterCacheControl = "public, max-age=86400"
+)
+
+var posterSizes = map[string]int{
+	"w92":      92,
+	"w185":     185,
+	"w342":     342,
+	"w500":     500,
+	"w780":     780,
+	"original": 0,
+}
+
+var errInvalidPoster = errors.New("poster must be a JPEG, PNG or GIF image")
+
+func (app *application) GetImage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	size := chi.URLParam(r, "size")
+	width, ok := posterSizes[size]
+
+	if !ok {
+		app.errorJSON(w, errors.New("unknown image size"), http.StatusNotFound)
+		return
+	}
+
+	movie, err := app.DB.GetMovieByID(id)
+
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && movie.PosterHash == "") {
+		app.errorJSON(w, errors.New("image not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	etag := fmt.Sprintf(`"%s-%s"`, movie.PosterHash[:16], size)
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", posterCacheControl)
+
+	if strings.Contains(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	data, err := app.posterVariant(movie.ID, movie.PosterHash, size, width)
+
+	if errors.Is(err, blobstore.ErrNotFound) {
+		app.errorJSON(w, errors.New("image not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+}
+
+func (app *application) UploadPoster(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPosterBytes+1<<20)
+
+	file, _, err := r.FormFile("poster")
+
+	if err != nil {
+		app.errorJSON(w, fmt.Errorf("poster file is required: %w", err))
+		return
+	}
+
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxPosterBytes+1))
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if len(data) > maxPosterBytes {
+		app.errorJSON(w, errors.New("poster must not be larger than 10MB"), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	movie, err := app.DB.GetMovieByID(id)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		app.errorJSON(w, errors.New("movie not found"), http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	err = app.storePoster(movie, data, posterSourceUpload)
+
+	if errors.Is(err, errInvalidPoster) {
+		app.validationErrorJSON(w, dtos.ValidationErrors{"poster": err.Error()})
+		return
+	}
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	response := dtos.JSONResponse{
+		Error:   false,
+		Message: "Poster successfuly uploaded",
+		Data:    movie,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, response)
+}
+
+func (app *application) posterVariant(movieID int, posterHash string, size string, width int) ([]byte, error) {
+	data, err := app.posters.Get(posterKey(movieID, posterHash, size))
+
+	if err == nil || size == "original" || !errors.Is(err, blobstore.ErrNotFound) {
+		return data, err
+	}
+
+	original, err := app.posters.Get(posterKey(movieID, posterHash, "original"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = imaging.ResizeJPEG(original, width, posterQuality)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.posters.Put(posterKey(movieID, posterHash, size), data, "image/jpeg")
+
+	if err != nil {
+		log.Printf("caching poster %d %s: %v", movieID, size, err)
+	}
+
+	return data, nil
+}
+
+func (app *application) downloadPoster(movie *models.Movie) error {
+	stored, err := app.DB.GetMovieByID(movie.ID)
+
+	if err != nil {
+		return err
+	}
+
+	movie.PosterHash = stored.PosterHash
+	movie.PosterSource = stored.PosterSource
+
+	if !strings.HasPrefix(movie.Image, "/") && movie.PosterHash != "" && movie.PosterSource != posterSourceUpload {
+		return app.clearPoster(movie)
+	}
+
+	if !strings.HasPrefix(movie.Image, "/") || movie.PosterSource == posterSourceUpload || movie.PosterSource == movie.Image {
+		return nil
+	}
+
+	configuration, err := app.tmdb.GetConfiguration()
+
+	if err != nil {
+		return err
+	}
+
+	baseURL := configuration.Images.SecureBaseURL
+
+	if baseURL == "" {
+		baseURL = configuration.Images.BaseURL
+	}
+
+	response, err := app.posterClient.Get(baseURL + "original" + movie.Image)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("downloading poster %s returned %d", movie.Image, response.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(response.Body, maxPosterBytes+1))
+
+	if err != nil {
+		return err
+	}
+
+	if len(data) > maxPosterBytes {
+		return fmt.Errorf("poster %s is larger than 10MB", movie.Image)
+	}
+
+	return app.storePoster(movie, data, movie.Image)
+}
+
+func (app *application) storePoster(movie *models.Movie, data []byte, source string) error {
+	_, format, err := imaging.Decode(data)
+
+	if err != nil {
+		return errInvalidPoster
+	}
+
+	sum := sha256.Sum256(data)
+	posterHash := hex.EncodeToString(sum[:])
+	previousHash := movie.PosterHash
+
+	err = app.posters.Put(posterKey(movie.ID, posterHash, "original"), data, "image/"+format)
+
+	if err != nil {
+		return err
+	}
+
+	err = app.DB.SetMoviePoster(movie.ID, posterHash, source)
+
+	if err != nil {
+		return err
+	}
+
+	movie.PosterHash = posterHash
+	movie.PosterSource = source
+
+	if previousHash != "" && previousHash != posterHash {
+		app.deletePoster(movie.ID, previousHash)
+	}
+
+	return nil
+}
+
+func (app *application) clearPoster(movie *models.Movie) error {
+	err := app.DB.SetMoviePoster(movie.ID, "", "")
+
+	if err != nil {
+		return err
+	}
+
+	app.deletePoster(movie.ID, movie.PosterHash)
+
+	movie.PosterHash = ""
+	movie.PosterSource = ""
+
+	return nil
+}
+
+func (app *application) deletePoster(movieID int, posterHash string) {
+	for size := range posterSizes {
+		err := app.posters.Delete(posterKey(movieID, posterHash, size))
+
+		if err != nil {
+			log.Printf("deleting poster %d %s: %v", movieID, size, err)
+		}
+	}
+}
+
+func posterKey(movieID int, posterHash string, size string) string {
+	return fmt.Sprintf("posters/%d/%s/%s", movieID, posterHash, size)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"backend/internal/models"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -14,21 +15,64 @@ func (app *application) routes() http.Handler {
 	mux.Use(app.enableCORS)
 
 	mux.Get("/", app.Home)
+	mux.Get("/.well-known/jwks.json", app.JWKS)
 	mux.Get("/movies", app.GetMovies)
+	mux.Get("/movies/search", app.SearchMovies)
 	mux.Get("/movies/{id}", app.GetMovie)
+	mux.Get("/images/{id}/{size}", app.GetImage)
 	mux.Get("/genres", app.GetGenres)
+	mux.Get("/genres/{id}/movies", app.GetMoviesByGenre)
+	mux.Post("/register", app.Register)
 	mux.Post("/authenticate", app.Authenticate)
+	mux.Post("/authenticate/mfa", app.AuthenticateMFA)
+	mux.Post("/password/forgot", app.ForgotPassword)
+	mux.Post("/password/reset", app.ResetPassword)
+	mux.Get("/auth/oidc/login", app.OIDCLogin)
+	mux.Get("/auth/oidc/callback", app.OIDCCallback)
 	mux.Get("/refresh", app.RefreshToken)
 	mux.Get("/logout", app.Logout)
 
+	mux.Route("/me", func(mux chi.Router) {
+		mux.Use(app.authRequired, app.denyAPIKeys)
+
+		mux.Get("/", app.GetMe)
+		mux.Patch("/", app.UpdateMe)
+		mux.Post("/password", app.ChangePassword)
+
+		if app.TOTPEnabled {
+			mux.Post("/2fa/enroll", app.EnrollTOTP)
+			mux.Post("/2fa/verify", app.VerifyTOTP)
+		}
+
+		if app.oidc != nil {
+			mux.Post("/oidc/link", app.LinkOIDCIdentity)
+		}
+	})
+
 	mux.Route("/admin", func(mux chi.Router) {
 		mux.Use(app.authRequired)
 
 		mux.Get("/catalogue", app.GetMoviesCatalogue)
-		mux.Put("/movies/create", app.SaveMovie)
-		mux.Post("/movies/import", app.ImportMovies)
-		mux.Patch("/movies/{id}", app.SaveMovie)
-		mux.Delete("/movies/{id}", app.DeleteMovie)
+		mux.With(app.requireRole(models.RoleEditor)).Get("/movies/export", app.ExportMovies)
+		mux.With(app.requireRole(models.RoleEditor)).Put("/movies/create", app.SaveMovie)
+		mux.With(app.requireRole(models.RoleEditor)).Post("/movies/import", app.ImportMovies)
+		mux.With(app.requireRole(models.RoleEditor)).Post("/movies/import/confirm", app.ConfirmImport)
+		mux.With(app.requireRole(models.RoleEditor)).Post("/movies/import/file", app.ImportMoviesFile)
+		mux.With(app.requireRole(models.RoleEditor)).Patch("/movies/{id}", app.SaveMovie)
+		mux.With(app.requireRole(models.RoleEditor)).Post("/movies/{id}/resync", app.ResyncMovie)
+		mux.With(app.requireRole(models.RoleEditor)).Post("/movies/{id}/poster", app.UploadPoster)
+		mux.With(app.requireRole(models.RoleAdmin)).Delete("/movies/{id}", app.DeleteMovie)
+		mux.With(app.requireRole(models.RoleEditor)).Get("/imports/{id}", app.GetImportJob)
+		mux.With(app.requireRole(models.RoleEditor)).Post("/imports/{id}/cancel", app.CancelImportJob)
+		mux.With(app.requireRole(models.RoleAdmin)).Post("/users/{id}/unlock", app.UnlockUser)
+
+		mux.Route("/api-keys", func(mux chi.Router) {
+			mux.Use(app.denyAPIKeys, app.requireRole(models.RoleAdmin))
+
+			mux.Get("/", app.GetAPIKeys)
+			mux.Post("/", app.CreateAPIKey)
+			mux.Delete("/{id}", app.RevokeAPIKey)
+		})
 	})
 
 	return mux
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"backend/internal/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRouteGates(t *testing.T) {
+	repository := &fakeRepository{}
+	viewer := repository.addUser(&models.User{Email: "viewer@example.com", Role: models.RoleViewer})
+	editor := repository.addUser(&models.User{Email: "editor@example.com", Role: models.RoleEditor})
+	admin := repository.addUser(&models.User{Email: "admin@example.com", Role: models.RoleAdmin})
+
+	repository.importJobs = append(repository.importJobs, &models.ImportJob{ID: 1, CreatedBy: editor.ID, Status: models.ImportJobQueued})
+
+	adminKey, _, err := generateAPIKey()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repository.addAPIKey(&models.APIKey{UserID: admin.ID, KeyHash: hashToken(adminKey), Role: models.RoleAdmin, OwnerRole: models.RoleAdmin})
+
+	app := &application{DB: repository, auth: newTestAuth()}
+	routes := app.routes()
+
+	bearer := func(user *models.User) string {
+		tokenPair, err := app.auth.GenerateTokenPair(user)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return "Bearer " + tokenPair.Token
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		apiKey        string
+		wantStatus    int
+	}{
+		{name: "no token on /me", method: http.MethodGet, path: "/me", wantStatus: http.StatusUnauthorized},
+		{name: "no token on an editor route", method: http.MethodGet, path: "/admin/imports/1", wantStatus: http.StatusUnauthorized},
+		{name: "invalid token", method: http.MethodGet, path: "/admin/imports/1", authorization: "Bearer not-a-token", wantStatus: http.StatusUnauthorized},
+		{name: "viewer on /me", method: http.MethodGet, path: "/me", authorization: bearer(viewer), wantStatus: http.StatusOK},
+		{name: "viewer on an editor route", method: http.MethodGet, path: "/admin/imports/1", authorization: bearer(viewer), wantStatus: http.StatusForbidden},
+		{name: "editor on an editor route", method: http.MethodGet, path: "/admin/imports/1", authorization: bearer(editor), wantStatus: http.StatusOK},
+		{name: "editor on an admin route", method: http.MethodPost, path: "/admin/users/1/unlock", authorization: bearer(editor), wantStatus: http.StatusForbidden},
+		{name: "editor on api keys", method: http.MethodGet, path: "/admin/api-keys", authorization: bearer(editor), wantStatus: http.StatusForbidden},
+		{name: "admin on api keys", method: http.MethodGet, path: "/admin/api-keys", authorization: bearer(admin), wantStatus: http.StatusOK},
+		{name: "admin on an editor route", method: http.MethodGet, path: "/admin/imports/1", authorization: bearer(admin), wantStatus: http.StatusOK},
+		{name: "api key on an editor route", method: http.MethodGet, path: "/admin/imports/1", apiKey: adminKey, wantStatus: http.StatusOK},
+		{name: "api key on /me", method: http.MethodGet, path: "/me", apiKey: adminKey, wantStatus: http.StatusForbidden},
+		{name: "api key on api keys", method: http.MethodGet, path: "/admin/api-keys", apiKey: adminKey, wantStatus: http.StatusForbidden},
+		{name: "api key as bearer on api keys", method: http.MethodGet, path: "/admin/api-keys", authorization: "ApiKey " + adminKey, wantStatus: http.StatusForbidden},
+		{name: "unknown api key", method: http.MethodGet, path: "/admin/imports/1", apiKey: "unknown", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, test.path, nil)
+
+			if test.authorization != "" {
+				request.Header.Set("Authorization", test.authorization)
+			}
+
+			if test.apiKey != "" {
+				request.Header.Set("X-API-Key", test.apiKey)
+			}
+
+			recorder := httptest.NewRecorder()
+			routes.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.wantStatus {
+				t.Errorf("expected %d, got %d: %s", test.wantStatus, recorder.Code, recorder.Body)
+			}
+		})
+	}
+}
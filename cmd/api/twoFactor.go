@@ -0,0 +1,250 @@
+package main
+
+import (
+	"backend/internal/dtos"
+	"backend/internal/totp"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const recoveryCodeCount = 10
+
+func (app *application) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	if user.TOTPEnabled {
+		app.errorJSON(w, errors.New("two-factor authentication is already enabled"), http.StatusConflict)
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	encryptedSecret, err := app.secrets.Seal(secret)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.DB.SetUserTOTPSecret(user.ID, encryptedSecret)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	var payload = struct {
+		Secret string `json:"secret"`
+		URI    string `json:"otpauth_uri"`
+	}{
+		Secret: secret,
+		URI:    totp.URI(app.TOTPIssuer, user.Email, secret),
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, payload)
+}
+
+func (app *application) VerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	user, err := app.currentUser(r)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusUnauthorized)
+		return
+	}
+
+	var requestPayload struct {
+		Code string `json:"code"`
+	}
+
+	err = app.readJSON(w, r, &requestPayload)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if user.TOTPEnabled {
+		app.errorJSON(w, errors.New("two-factor authentication is already enabled"), http.StatusConflict)
+		return
+	}
+
+	if user.TOTPSecret == "" {
+		app.errorJSON(w, errors.New("two-factor enrollment has not been started"))
+		return
+	}
+
+	secret, err := app.secrets.Open(user.TOTPSecret)
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	step, valid := totp.Validate(secret, strings.TrimSpace(requestPayload.Code), time.Now(), 1)
+
+	if !valid {
+		app.validationErrorJSON(w, dtos.ValidationErrors{"code": "is invalid"})
+		return
+	}
+
+	used, err := app.DB.UseTOTPStep(user.ID, step)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	if !used {
+		app.errorJSON(w, errors.New("code has already been used"), http.StatusUnauthorized)
+		return
+	}
+
+	recoveryCodes, recoveryCodeHashes, err := generateRecoveryCodes()
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	err = app.DB.EnableUserTOTP(user.ID, recoveryCodeHashes)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	var payload = struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}{
+		RecoveryCodes: recoveryCodes,
+	}
+
+	_ = app.writeJSON(w, http.StatusOK, payload)
+}
+
+func (app *application) AuthenticateMFA(w http.ResponseWriter, r *http.Request) {
+	var requestPayload struct {
+		MFAToken string `json:"mfa_token"`
+		Code     string `json:"code"`
+	}
+
+	err := app.readJSON(w, r, &requestPayload)
+
+	if err != nil {
+		app.errorJSON(w, err)
+		return
+	}
+
+	claims, err := app.auth.ParseMFAToken(requestPayload.MFAToken)
+
+	if err != nil {
+		app.errorJSON(w, errors.New("invalid or expired two-factor challenge"), http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := strconv.Atoi(claims.Subject)
+
+	if err != nil {
+		app.errorJSON(w, errors.New("unkown user"), http.StatusUnauthorized)
+		return
+	}
+
+	user, err := app.DB.GetUserByID(userID)
+
+	if err != nil || !user.TOTPEnabled {
+		app.errorJSON(w, errors.New("unkown user"), http.StatusUnauthorized)
+		return
+	}
+
+	retryAfter := app.loginRetryAfter(r, user.Email)
+
+	if retryAfter > 0 {
+		app.tooManyAttemptsJSON(w, retryAfter)
+		return
+	}
+
+	valid, err := app.verifySecondFactor(user.ID, user.TOTPSecret, strings.TrimSpace(requestPayload.Code))
+
+	if err != nil {
+		app.errorJSON(w, err, http.StatusInternalServerError)
+		return
+	}
+
+	if !valid {
+		app.recordLoginFailure(r, user.Email)
+		app.errorJSON(w, errors.New("invalid two-factor code"), http.StatusUnauthorized)
+		return
+	}
+
+	app.recordLoginSuccess(user.Email)
+
+	app.startSession(w, user)
+}
+
+func (app *application) verifySecondFactor(userID int, encryptedSecret string, code string) (bool, error) {
+	if app.secrets == nil {
+		return false, errors.New("two-factor authentication is not configured")
+	}
+
+	secret, err := app.secrets.Open(encryptedSecret)
+
+	if err != nil {
+		return false, err
+	}
+
+	step, valid := totp.Validate(secret, code, time.Now(), 1)
+
+	if valid {
+		return app.DB.UseTOTPStep(userID, step)
+	}
+
+	used, err := app.DB.UseRecoveryCode(userID, hashToken(normalizeRecoveryCode(code)))
+
+	if err != nil {
+		log.Println(err)
+		return false, nil
+	}
+
+	return used, nil
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	for i := range codes {
+		bytes := make([]byte, 5)
+
+		_, err := rand.Read(bytes)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := hex.EncodeToString(bytes)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashToken(normalizeRecoveryCode(codes[i]))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, "-", ""))
+}
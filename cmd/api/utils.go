@@ -71,6 +71,16 @@ func (app *application) errorJSON(w http.ResponseWriter, err error, status ...in
 	app.writeJSON(w, statusCode, payload)
 }
 
+func (app *application) validationErrorJSON(w http.ResponseWriter, errs dtos.ValidationErrors) {
+	payload := dtos.JSONResponse{
+		Error:   true,
+		Message: "invalid input",
+		Data:    errs,
+	}
+
+	app.writeJSON(w, http.StatusUnprocessableEntity, payload)
+}
+
 func (app *application) fromRequestToMovie(w http.ResponseWriter, r *http.Request) (*models.Movie, error) {
 	var movie models.Movie
 
@@ -80,7 +90,5 @@ func (app *application) fromRequestToMovie(w http.ResponseWriter, r *http.Reques
 		return nil, err
 	}
 
-	app.downloadMovieData(&movie)
-
 	return &movie, nil
 }
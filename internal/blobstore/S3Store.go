@@ -0,0 +1,186 @@
+package blobstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+type S3Store struct {
+	Endpoint  string
+	Bucket    string
+	Region    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+	HTTP      *http.Client
+}
+
+func (s *S3Store) Put(key string, data []byte, contentType string) error {
+	request, err := s.newRequest("PUT", key, data)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", contentType)
+
+	_, err = s.do(request, data)
+
+	return err
+}
+
+func (s *S3Store) Get(key string) ([]byte, error) {
+	request, err := s.newRequest("GET", key, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.do(request, nil)
+}
+
+func (s *S3Store) Delete(key string) error {
+	request, err := s.newRequest("DELETE", key, nil)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.do(request, nil)
+
+	if err == ErrNotFound {
+		return nil
+	}
+
+	return err
+}
+
+func (s *S3Store) newRequest(method string, key string, data []byte) (*http.Request, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+
+	if err != nil {
+		return nil, err
+	}
+
+	path := "/" + key
+
+	if s.PathStyle {
+		path = "/" + s.Bucket + path
+	} else {
+		endpoint.Host = s.Bucket + "." + endpoint.Host
+	}
+
+	endpoint.Path = path
+
+	return http.NewRequest(method, endpoint.String(), bytes.NewReader(data))
+}
+
+func (s *S3Store) do(request *http.Request, payload []byte) ([]byte, error) {
+	s.sign(request, payload, time.Now().UTC())
+
+	client := s.HTTP
+
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	response, err := client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("s3 %s %s returned %d: %s", request.Method, request.URL.Path, response.StatusCode, body)
+	}
+
+	return body, nil
+}
+
+func (s *S3Store) sign(request *http.Request, payload []byte, now time.Time) {
+	payloadHash := sha256.Sum256(payload)
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", hex.EncodeToString(payloadHash[:]))
+
+	headers := map[string]string{"host": request.URL.Host}
+
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	var headerNames []string
+
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+
+	sort.Strings(headerNames)
+
+	var canonicalHeaders strings.Builder
+
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(headerNames, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + s.Region + "/s3/aws4_request"
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, s.Region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,11 @@
+package blobstore
+
+import "errors"
+
+var ErrNotFound = errors.New("blob not found")
+
+type Store interface {
+	Put(key string, data []byte, contentType string) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
@@ -0,0 +1,14 @@
+package dtos
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (p *ChangePassword) Validate() ValidationErrors {
+	errs := ValidationErrors{}
+	errs.Check(p.CurrentPassword != "", "current_password", "must be provided")
+	errs.CheckPassword(p.NewPassword, "new_password")
+
+	return errs
+}
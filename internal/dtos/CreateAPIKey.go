@@ -0,0 +1,23 @@
+package dtos
+
+import (
+	"backend/internal/models"
+	"strings"
+)
+
+type CreateAPIKey struct {
+	Name          string `json:"name"`
+	Role          string `json:"role"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+func (k *CreateAPIKey) Validate() ValidationErrors {
+	k.Name = strings.TrimSpace(k.Name)
+
+	errs := ValidationErrors{}
+	errs.CheckName(k.Name, "name")
+	errs.Check(models.IsValidRole(k.Role), "role", "must be viewer, editor or admin")
+	errs.Check(k.ExpiresInDays >= 0, "expires_in_days", "must not be negative")
+
+	return errs
+}
@@ -0,0 +1,15 @@
+package dtos
+
+import "backend/internal/models"
+
+type EnrichmentReport struct {
+	Mode    string   `json:"mode"`
+	Fields  []string `json:"fields"`
+	Changed []string `json:"changed"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type SavedMovie struct {
+	Movie      *models.Movie     `json:"movie"`
+	Enrichment *EnrichmentReport `json:"enrichment,omitempty"`
+}
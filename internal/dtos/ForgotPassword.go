@@ -0,0 +1,16 @@
+package dtos
+
+import "strings"
+
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+func (p *ForgotPassword) Validate() ValidationErrors {
+	p.Email = strings.ToLower(strings.TrimSpace(p.Email))
+
+	errs := ValidationErrors{}
+	errs.CheckEmail(p.Email, "email")
+
+	return errs
+}
@@ -0,0 +1,58 @@
+package dtos
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	minImportYear = 1888
+	maxImportYear = 2100
+)
+
+type ImportFileRow struct {
+	Title       string   `json:"title"`
+	Year        int      `json:"year"`
+	Runtime     int      `json:"runtime"`
+	MPAARating  string   `json:"mpaa_rating"`
+	Genres      []string `json:"genres"`
+	Description string   `json:"description"`
+	TMDBID      int      `json:"tmdb_id"`
+}
+
+func (i *ImportFileRow) Validate() ValidationErrors {
+	i.Title = strings.TrimSpace(i.Title)
+	i.MPAARating = strings.TrimSpace(i.MPAARating)
+
+	errs := ValidationErrors{}
+	errs.Check(i.Title != "", "title", "must be provided")
+	errs.Check(utf8.RuneCountInString(i.Title) <= 512, "title", "must not be longer than 512 characters")
+	errs.Check(i.Year >= minImportYear && i.Year <= maxImportYear, "year", "must be between 1888 and 2100")
+	errs.Check(i.Runtime >= 0, "runtime", "must not be negative")
+	errs.Check(utf8.RuneCountInString(i.MPAARating) <= 10, "mpaa_rating", "must not be longer than 10 characters")
+	errs.Check(i.TMDBID >= 0, "tmdb_id", "must not be negative")
+
+	for _, genre := range i.Genres {
+		errs.Check(strings.TrimSpace(genre) != "", "genres", "must not contain empty names")
+	}
+
+	return errs
+}
+
+type ImportFileIssue struct {
+	Line   int              `json:"line"`
+	Title  string           `json:"title,omitempty"`
+	Reason string           `json:"reason,omitempty"`
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+type ImportFileReport struct {
+	Format   string            `json:"format"`
+	Mode     string            `json:"mode"`
+	Total    int               `json:"total"`
+	Imported int               `json:"imported"`
+	MovieIDs []int             `json:"movie_ids"`
+	Skipped  []ImportFileIssue `json:"skipped"`
+	Failed   []ImportFileIssue `json:"failed"`
+	Warnings []ImportFileIssue `json:"warnings,omitempty"`
+}
@@ -1,9 +1,63 @@
 package dtos
 
+import "strings"
+
+const (
+	maxSelectedImports = 100
+	MaxImportPages     = 50
+)
+
 type ImportMovies struct {
 	PivotWords string `json:"pivot_words"`
 	OtherWords string `json:"other_words"`
 	Rating     string `json:"rating"`
 	Votes      string `json:"votes"`
 	Year       string `json:"year"`
+	MaxPages   int    `json:"max_pages,omitempty"`
+	MaxResults int    `json:"max_results,omitempty"`
+	DryRun     bool   `json:"dry_run,omitempty"`
+	TMDBIDs    []int  `json:"tmdb_ids,omitempty"`
+}
+
+func (i *ImportMovies) Validate() ValidationErrors {
+	errs := ValidationErrors{}
+	errs.Check(strings.TrimSpace(i.PivotWords) != "", "pivot_words", "must be provided")
+	errs.Check(i.MaxPages >= 0 && i.MaxPages <= MaxImportPages, "max_pages", "must be between 0 and 50")
+	errs.Check(i.MaxResults >= 0, "max_results", "must not be negative")
+
+	return errs
+}
+
+type ConfirmImport struct {
+	TMDBIDs []int `json:"tmdb_ids"`
+}
+
+func (c *ConfirmImport) Validate() ValidationErrors {
+	errs := ValidationErrors{}
+	errs.Check(len(c.TMDBIDs) > 0, "tmdb_ids", "must contain at least one id")
+	errs.Check(len(c.TMDBIDs) <= maxSelectedImports, "tmdb_ids", "must not contain more than 100 ids")
+
+	for _, id := range c.TMDBIDs {
+		errs.Check(id > 0, "tmdb_ids", "must only contain positive ids")
+	}
+
+	return errs
+}
+
+type ImportCandidate struct {
+	TMDBID      int      `json:"tmdb_id"`
+	Title       string   `json:"title"`
+	Overview    string   `json:"overview,omitempty"`
+	ReleaseDate string   `json:"release_date"`
+	Year        int      `json:"year,omitempty"`
+	Rating      float32  `json:"rating"`
+	Votes       int      `json:"votes"`
+	Poster      string   `json:"poster"`
+	Reasons     []string `json:"reasons,omitempty"`
+}
+
+type ImportPreview struct {
+	Candidates []ImportCandidate `json:"candidates"`
+	Filtered   []ImportCandidate `json:"filtered"`
+	Errors     []string          `json:"errors,omitempty"`
 }
@@ -0,0 +1,10 @@
+package dtos
+
+import "backend/internal/models"
+
+type MovieSearchResult struct {
+	*models.Movie
+	Rank           float32 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"`
+	Snippet        string  `json:"snippet"`
+}
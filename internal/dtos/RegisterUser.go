@@ -0,0 +1,22 @@
+package dtos
+
+import "strings"
+
+type RegisterUser struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+func (u *RegisterUser) Validate() ValidationErrors {
+	u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+
+	errs := ValidationErrors{}
+	errs.CheckName(u.FirstName, "first_name")
+	errs.CheckName(u.LastName, "last_name")
+	errs.CheckEmail(u.Email, "email")
+	errs.CheckPassword(u.Password, "password")
+
+	return errs
+}
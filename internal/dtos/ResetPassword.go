@@ -0,0 +1,14 @@
+package dtos
+
+type ResetPassword struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+func (p *ResetPassword) Validate() ValidationErrors {
+	errs := ValidationErrors{}
+	errs.Check(p.Token != "", "token", "must be provided")
+	errs.CheckPassword(p.Password, "password")
+
+	return errs
+}
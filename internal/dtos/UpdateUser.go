@@ -0,0 +1,30 @@
+package dtos
+
+import "strings"
+
+type UpdateUser struct {
+	FirstName *string `json:"first_name"`
+	LastName  *string `json:"last_name"`
+	Email     *string `json:"email"`
+}
+
+func (u *UpdateUser) Validate() ValidationErrors {
+	errs := ValidationErrors{}
+
+	if u.FirstName != nil {
+		errs.CheckName(*u.FirstName, "first_name")
+	}
+
+	if u.LastName != nil {
+		errs.CheckName(*u.LastName, "last_name")
+	}
+
+	if u.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*u.Email))
+		u.Email = &email
+
+		errs.CheckEmail(email, "email")
+	}
+
+	return errs
+}
@@ -0,0 +1,40 @@
+package dtos
+
+import (
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+const minPasswordLength = 8
+
+type ValidationErrors map[string]string
+
+func (v ValidationErrors) Add(field string, message string) {
+	if _, exists := v[field]; !exists {
+		v[field] = message
+	}
+}
+
+func (v ValidationErrors) Check(ok bool, field string, message string) {
+	if !ok {
+		v.Add(field, message)
+	}
+}
+
+func (v ValidationErrors) CheckName(value string, field string) {
+	v.Check(strings.TrimSpace(value) != "", field, "must be provided")
+	v.Check(utf8.RuneCountInString(value) <= 255, field, "must not be longer than 255 characters")
+}
+
+func (v ValidationErrors) CheckEmail(value string, field string) {
+	address, err := mail.ParseAddress(value)
+
+	v.Check(err == nil && address.Address == value, field, "must be a valid email address")
+	v.Check(len(value) <= 255, field, "must not be longer than 255 characters")
+}
+
+func (v ValidationErrors) CheckPassword(value string, field string) {
+	v.Check(utf8.RuneCountInString(value) >= minPasswordLength, field, "must be at least 8 characters long")
+	v.Check(len(value) <= 72, field, "must not be longer than 72 bytes")
+}
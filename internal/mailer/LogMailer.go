@@ -0,0 +1,11 @@
+package mailer
+
+import "log"
+
+type LogMailer struct{}
+
+func (m *LogMailer) Send(message Message) error {
+	log.Printf("Mail to %s: %s\n%s", message.To, message.Subject, message.Body)
+
+	return nil
+}
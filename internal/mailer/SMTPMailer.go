@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Validate() error {
+	_, err := mail.ParseAddress(m.From)
+
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", m.From, err)
+	}
+
+	return nil
+}
+
+func (m *SMTPMailer) Send(message Message) error {
+	from, err := mail.ParseAddress(m.From)
+
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", m.From, err)
+	}
+
+	var auth smtp.Auth
+
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	var body strings.Builder
+
+	fmt.Fprintf(&body, "From: %s\r\n", from.String())
+	fmt.Fprintf(&body, "To: %s\r\n", message.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", message.Subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", m.Host, m.Port), auth, from.Address, []string{message.To}, []byte(body.String()))
+}
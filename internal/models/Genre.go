@@ -5,6 +5,7 @@ import "time"
 type Genre struct {
 	ID        int       `json:"id"`
 	Name      string    `json:"name"`
+	TMDBID    int       `json:"tmdb_id,omitempty"`
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
 }
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ImportJobQueued    = "queued"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+	ImportJobCancelled = "cancelled"
+)
+
+type ImportJob struct {
+	ID         int              `json:"id"`
+	Status     string           `json:"status"`
+	Params     json.RawMessage  `json:"params"`
+	Considered int              `json:"considered"`
+	Skipped    int              `json:"skipped"`
+	Imported   int              `json:"imported"`
+	Failed     int              `json:"failed"`
+	Attempt    int              `json:"attempt"`
+	Error      string           `json:"error,omitempty"`
+	Errors     []ImportJobError `json:"errors"`
+	CreatedBy  int              `json:"created_by,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
+
+type ImportJobError struct {
+	TMDBID  int    `json:"tmdb_id,omitempty"`
+	Title   string `json:"title,omitempty"`
+	Reason  string `json:"reason"`
+	Attempt int    `json:"attempt"`
+}
+
+func (j *ImportJob) IsFinished() bool {
+	return j.Status == ImportJobCompleted || j.Status == ImportJobFailed || j.Status == ImportJobCancelled
+}
@@ -3,15 +3,22 @@ package models
 import "time"
 
 type Movie struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	ReleaseDate time.Time `json:"release_date"`
-	Duration    int       `json:"duration"`
-	MPAARating  string    `json:"mpaa_rating"`
-	Rating      float32   `json:"rating"`
-	VoteCount   int       `json:"vote_count"`
-	Description string    `json:"description"`
-	Image       string    `json:"image"`
-	CreatedAt   time.Time `json:"-"`
-	UpdatedAt   time.Time `json:"-"`
+	ID           int       `json:"id"`
+	Title        string    `json:"title"`
+	ReleaseDate  time.Time `json:"release_date"`
+	Duration     int       `json:"duration"`
+	MPAARating   string    `json:"mpaa_rating"`
+	Rating       float32   `json:"rating"`
+	VoteCount    int       `json:"vote_count"`
+	Description  string    `json:"description"`
+	Image        string    `json:"image"`
+	Genres       []*Genre  `json:"genres,omitempty"`
+	GenresArray  []int     `json:"genres_array,omitempty"`
+	CreatedAt    time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+	CreatedBy    int       `json:"created_by,omitempty"`
+	UpdatedBy    int       `json:"updated_by,omitempty"`
+	TMDBID       int       `json:"tmdb_id,omitempty"`
+	PosterHash   string    `json:"poster_hash,omitempty"`
+	PosterSource string    `json:"-"`
 }
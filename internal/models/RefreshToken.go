@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	ID         int        `json:"id"`
+	JTI        string     `json:"jti"`
+	FamilyID   string     `json:"family_id"`
+	UserID     int        `json:"user_id"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy string     `json:"replaced_by,omitempty"`
+	CreatedAt  time.Time  `json:"-"`
+}
+
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
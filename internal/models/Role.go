@@ -0,0 +1,23 @@
+package models
+
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+func IsValidRole(role string) bool {
+	_, ok := roleRanks[role]
+
+	return ok
+}
+
+func RoleSatisfies(role string, required string) bool {
+	return IsValidRole(role) && roleRanks[role] >= roleRanks[required]
+}
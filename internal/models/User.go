@@ -7,14 +7,31 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+const passwordCost = 12
+
 type User struct {
-	ID        int       `json:"id"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Email     string    `json:"email"`
-	Password  string    `json:"pasword"`
-	CreatedAt time.Time `json:"-"`
-	UpdatedAt time.Time `json:"-"`
+	ID          int       `json:"id"`
+	FirstName   string    `json:"first_name"`
+	LastName    string    `json:"last_name"`
+	Email       string    `json:"email"`
+	Password    string    `json:"-"`
+	Role        string    `json:"role"`
+	TOTPSecret  string    `json:"-"`
+	TOTPEnabled bool      `json:"totp_enabled"`
+	CreatedAt   time.Time `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+func (u *User) SetPassword(plainText string) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(plainText), passwordCost)
+
+	if err != nil {
+		return err
+	}
+
+	u.Password = string(hash)
+
+	return nil
 }
 
 func (u *User) PasswordMatches(plainText string) (bool, error) {
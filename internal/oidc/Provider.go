@@ -0,0 +1,322 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const minKeyRefreshInterval = time.Minute
+
+type Config struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+type IDTokenClaims struct {
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GivenName     string `json:"given_name"`
+	FamilyName    string `json:"family_name"`
+	Name          string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+type Provider struct {
+	Config     Config
+	HTTPClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *Discovery
+	keys          map[string]any
+	keysFetchedAt time.Time
+}
+
+func NewProvider(config Config) *Provider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+
+	return &Provider{
+		Config:     config,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *Provider) Discover() (*Discovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	var discovery Discovery
+
+	err := p.getJSON(strings.TrimSuffix(p.Config.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != strings.TrimSuffix(p.Config.IssuerURL, "/") {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", discovery.Issuer, p.Config.IssuerURL)
+	}
+
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+func (p *Provider) AuthCodeURL(state string, nonce string, codeVerifier string) (string, error) {
+	discovery, err := p.Discover()
+
+	if err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(codeVerifier))
+
+	values := url.Values{}
+	values.Set("response_type", "code")
+	values.Set("client_id", p.Config.ClientID)
+	values.Set("redirect_uri", p.Config.RedirectURL)
+	values.Set("scope", strings.Join(p.Config.Scopes, " "))
+	values.Set("state", state)
+	values.Set("nonce", nonce)
+	values.Set("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:]))
+	values.Set("code_challenge_method", "S256")
+
+	separator := "?"
+
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + values.Encode(), nil
+}
+
+func (p *Provider) Exchange(code string, codeVerifier string) (*TokenResponse, error) {
+	discovery, err := p.Discover()
+
+	if err != nil {
+		return nil, err
+	}
+
+	values := url.Values{}
+	values.Set("grant_type", "authorization_code")
+	values.Set("code", code)
+	values.Set("redirect_uri", p.Config.RedirectURL)
+	values.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequest("POST", discovery.TokenEndpoint, strings.NewReader(values.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	request.SetBasicAuth(url.QueryEscape(p.Config.ClientID), url.QueryEscape(p.Config.ClientSecret))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.HTTPClient.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s", response.StatusCode, body)
+	}
+
+	var tokenResponse TokenResponse
+
+	err = json.Unmarshal(body, &tokenResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return &tokenResponse, nil
+}
+
+func (p *Provider) VerifyIDToken(rawIDToken string, nonce string) (*IDTokenClaims, error) {
+	discovery, err := p.Discover()
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &IDTokenClaims{}
+
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "EdDSA"}))
+
+	_, err = parser.ParseWithClaims(rawIDToken, claims, p.keyFunc)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Issuer != discovery.Issuer {
+		return nil, errors.New("id token issuer mismatch")
+	}
+
+	if !claims.VerifyAudience(p.Config.ClientID, true) {
+		return nil, errors.New("id token audience mismatch")
+	}
+
+	if claims.Nonce != nonce {
+		return nil, errors.New("id token nonce mismatch")
+	}
+
+	if claims.Subject == "" {
+		return nil, errors.New("id token has no subject")
+	}
+
+	return claims, nil
+}
+
+func (p *Provider) keyFunc(token *jwt.Token) (any, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	key, err := p.key(keyID, false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		key, err = p.key(keyID, true)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		return nil, fmt.Errorf("unknown signing key %q", keyID)
+	}
+
+	return key, nil
+}
+
+func (p *Provider) key(keyID string, refresh bool) (any, error) {
+	discovery, err := p.Discover()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if refresh && time.Since(p.keysFetchedAt) < minKeyRefreshInterval {
+		refresh = false
+	}
+
+	if p.keys == nil || refresh {
+		var keySet struct {
+			Keys []jsonWebKey `json:"keys"`
+		}
+
+		err := p.getJSON(discovery.JWKSURI, &keySet)
+
+		if err != nil {
+			return nil, err
+		}
+
+		p.keys = map[string]any{}
+		p.keysFetchedAt = time.Now()
+
+		for _, jsonWebKey := range keySet.Keys {
+			if jsonWebKey.Use != "" && jsonWebKey.Use != "sig" {
+				continue
+			}
+
+			publicKey, err := jsonWebKey.PublicKey()
+
+			if err == nil {
+				p.keys[jsonWebKey.KeyID] = publicKey
+			}
+		}
+	}
+
+	return p.keys[keyID], nil
+}
+
+func (p *Provider) getJSON(uri string, target any) error {
+	request, err := http.NewRequest("GET", uri, nil)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Accept", "application/json")
+
+	response, err := p.HTTPClient.Do(request)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", uri, response.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(response.Body, 1<<20)).Decode(target)
+}
+
+func RandomString() (string, error) {
+	bytes := make([]byte, 32)
+
+	_, err := rand.Read(bytes)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
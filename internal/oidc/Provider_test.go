@@ -0,0 +1,331 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	testClientID = "movies"
+	testKeyID    = "test-key"
+	testCode     = "auth-code"
+)
+
+type stubProvider struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	idToken   string
+	jwksHits  atomic.Int32
+}
+
+func newStubProvider(t *testing.T) *stubProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stub := &stubProvider{key: key}
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(Discovery{
+			Issuer:                stub.server.URL,
+			AuthorizationEndpoint: stub.server.URL + "/authorize",
+			TokenEndpoint:         stub.server.URL + "/token",
+			JWKSURI:               stub.server.URL + "/jwks",
+		})
+	})
+
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		stub.jwksHits.Add(1)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []jsonWebKey{{
+				KeyType: "RSA",
+				KeyID:   testKeyID,
+				Use:     "sig",
+				N:       base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		err := r.ParseForm()
+
+		if err != nil || r.PostForm.Get("code") != testCode {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		challenge := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+
+		if base64.RawURLEncoding.EncodeToString(challenge[:]) != stub.challenge {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(TokenResponse{
+			AccessToken: "access-token",
+			TokenType:   "Bearer",
+			IDToken:     stub.idToken,
+			ExpiresIn:   300,
+		})
+	})
+
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+
+	return stub
+}
+
+func (s *stubProvider) provider() *Provider {
+	return NewProvider(Config{
+		IssuerURL:    s.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost/callback",
+	})
+}
+
+func (s *stubProvider) sign(t *testing.T, claims IDTokenClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = testKeyID
+
+	signed, err := token.SignedString(s.key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func validClaims(issuer string) IDTokenClaims {
+	return IDTokenClaims{
+		Nonce:         "nonce",
+		Email:         "user@example.com",
+		EmailVerified: true,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   "user-1",
+			Audience:  jwt.ClaimStrings{testClientID},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	stub := newStubProvider(t)
+
+	tests := []struct {
+		name    string
+		modify  func(claims *IDTokenClaims)
+		nonce   string
+		wantErr bool
+	}{
+		{
+			name:  "valid",
+			nonce: "nonce",
+		},
+		{
+			name:    "wrong issuer",
+			modify:  func(claims *IDTokenClaims) { claims.Issuer = "https://attacker.example.com" },
+			nonce:   "nonce",
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			modify:  func(claims *IDTokenClaims) { claims.Audience = jwt.ClaimStrings{"another-client"} },
+			nonce:   "nonce",
+			wantErr: true,
+		},
+		{
+			name:    "wrong nonce",
+			nonce:   "another-nonce",
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			modify:  func(claims *IDTokenClaims) { claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute)) },
+			nonce:   "nonce",
+			wantErr: true,
+		},
+		{
+			name:    "missing subject",
+			modify:  func(claims *IDTokenClaims) { claims.Subject = "" },
+			nonce:   "nonce",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			claims := validClaims(stub.server.URL)
+
+			if test.modify != nil {
+				test.modify(&claims)
+			}
+
+			verified, err := stub.provider().VerifyIDToken(stub.sign(t, claims), test.nonce)
+
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("expected an error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if verified.Subject != "user-1" || verified.Email != "user@example.com" {
+				t.Errorf("unexpected claims %+v", verified)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsForeignKey(t *testing.T) {
+	stub := newStubProvider(t)
+
+	foreignKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims(stub.server.URL))
+	token.Header["kid"] = testKeyID
+
+	signed, err := token.SignedString(foreignKey)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = stub.provider().VerifyIDToken(signed, "nonce")
+
+	if err == nil {
+		t.Fatal("expected a token signed by another key to be rejected")
+	}
+}
+
+func TestUnknownKeyIDRefetchesKeysAtMostOncePerInterval(t *testing.T) {
+	stub := newStubProvider(t)
+	provider := stub.provider()
+
+	_, err := provider.VerifyIDToken(stub.sign(t, validClaims(stub.server.URL)), "nonce")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims(stub.server.URL))
+		token.Header["kid"] = "unknown-key"
+
+		signed, err := token.SignedString(stub.key)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = provider.VerifyIDToken(signed, "nonce")
+
+		if err == nil {
+			t.Fatal("expected a token with an unknown key ID to be rejected")
+		}
+	}
+
+	if hits := stub.jwksHits.Load(); hits != 1 {
+		t.Errorf("expected 1 JWKS request, got %d", hits)
+	}
+
+	provider.keysFetchedAt = time.Now().Add(-minKeyRefreshInterval)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, validClaims(stub.server.URL))
+	token.Header["kid"] = "unknown-key"
+
+	signed, err := token.SignedString(stub.key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = provider.VerifyIDToken(signed, "nonce")
+
+	if hits := stub.jwksHits.Load(); hits != 2 {
+		t.Errorf("expected an unknown key ID to refetch the JWKS once the interval has passed, got %d requests", hits)
+	}
+}
+
+func TestPKCE(t *testing.T) {
+	stub := newStubProvider(t)
+	stub.idToken = stub.sign(t, validClaims(stub.server.URL))
+
+	provider := stub.provider()
+
+	authURL, err := provider.AuthCodeURL("state", "nonce", "verifier")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := url.Parse(authURL)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	query := parsed.Query()
+	expected := sha256.Sum256([]byte("verifier"))
+
+	if query.Get("code_challenge_method") != "S256" {
+		t.Errorf("expected code_challenge_method S256, got %q", query.Get("code_challenge_method"))
+	}
+
+	if query.Get("code_challenge") != base64.RawURLEncoding.EncodeToString(expected[:]) {
+		t.Errorf("code_challenge %q is not the S256 hash of the verifier", query.Get("code_challenge"))
+	}
+
+	stub.challenge = query.Get("code_challenge")
+
+	_, err = provider.Exchange(testCode, "another-verifier")
+
+	if err == nil {
+		t.Fatal("expected the token endpoint to reject a mismatched code_verifier")
+	}
+
+	tokens, err := provider.Exchange(testCode, "verifier")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tokens.IDToken != stub.idToken {
+		t.Errorf("expected the stub id_token, got %q", tokens.IDToken)
+	}
+}
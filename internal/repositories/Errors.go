@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrRefreshTokenRevoked = errors.New("refresh token already revoked")
+	ErrDuplicateEmail      = errors.New("email is already registered")
+	ErrInvalidResetToken   = errors.New("invalid or expired reset token")
+	ErrDuplicateTMDBID     = errors.New("a movie with this TMDB ID already exists")
+)
+
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
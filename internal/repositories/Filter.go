@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Operator string
+
+const (
+	Equal          Operator = "="
+	NotEqual       Operator = "<>"
+	LessThan       Operator = "<"
+	GreaterThan    Operator = ">"
+	LessOrEqual    Operator = "<="
+	GreaterOrEqual Operator = ">="
+	ILike          Operator = "ILIKE"
+	In             Operator = "IN"
+	Between        Operator = "BETWEEN"
+	IsNull         Operator = "IS NULL"
+)
+
+var (
+	ErrUnknownColumn   = errors.New("unknown filter column")
+	ErrUnknownOperator = errors.New("unknown filter operator")
+	ErrFilterArity     = errors.New("wrong number of filter values")
+)
+
+type Filter struct {
+	Column   string
+	Operator Operator
+	Values   []any
+}
+
+func NewFilter(column string, operator Operator, values ...any) Filter {
+	return Filter{
+		Column:   column,
+		Operator: operator,
+		Values:   values,
+	}
+}
+
+func (f Filter) Validate() error {
+	switch f.Operator {
+	case Equal, NotEqual, LessThan, GreaterThan, LessOrEqual, GreaterOrEqual, ILike:
+		if len(f.Values) != 1 {
+			return fmt.Errorf("%w: %s expects 1 value, got %d", ErrFilterArity, f.Operator, len(f.Values))
+		}
+	case In:
+		if len(f.Values) == 0 {
+			return fmt.Errorf("%w: %s expects at least 1 value", ErrFilterArity, f.Operator)
+		}
+	case Between:
+		if len(f.Values) != 2 {
+			return fmt.Errorf("%w: %s expects 2 values, got %d", ErrFilterArity, f.Operator, len(f.Values))
+		}
+	case IsNull:
+		if len(f.Values) != 0 {
+			return fmt.Errorf("%w: %s expects no values, got %d", ErrFilterArity, f.Operator, len(f.Values))
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownOperator, f.Operator)
+	}
+
+	return nil
+}
@@ -0,0 +1,10 @@
+package repositories
+
+type MovieQuery struct {
+	Filters    []Filter
+	GenreID    int
+	SortBy     string
+	Descending bool
+	Limit      int
+	Offset     int
+}
@@ -4,17 +4,53 @@ import (
 	"backend/internal/dtos"
 	"backend/internal/models"
 	"database/sql"
+	"time"
 )
 
 type Repository interface {
 	GetConnection() *sql.DB
 	SaveMovie(movie *models.Movie) error
+	SaveMovies(movies []*models.Movie) error
 	UpdateMovie(movie *models.Movie) error
-	GetMovies(searchColumns ...dtos.SearchColumn) ([]*models.Movie, error)
+	GetMovies(filters ...Filter) ([]*models.Movie, error)
 	GetAllMovies() ([]*models.Movie, error)
+	ListMovies(movieQuery MovieQuery) ([]*models.Movie, int, error)
+	StreamMovies(movieQuery MovieQuery, each func(movie *models.Movie) error) error
+	SearchMovies(search string, movieQuery MovieQuery) ([]*dtos.MovieSearchResult, int, error)
 	GetMovieByID(id int) (*models.Movie, error)
 	DeleteMovie(id int) error
-	GetGenres(searchColumns ...dtos.SearchColumn) ([]*models.Genre, error)
+	SetMoviePoster(id int, posterHash string, posterSource string) error
+	GetGenres(filters ...Filter) ([]*models.Genre, error)
+	UpsertTMDBGenres(genres []*models.Genre) error
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id int) (*models.User, error)
+	CreateUser(user *models.User) error
+	UpdateUser(user *models.User) error
+	UpdateUserPassword(id int, passwordHash string) error
+	GetUserByIdentity(issuer string, subject string) (*models.User, error)
+	LinkUserIdentity(userID int, issuer string, subject string, email string) error
+	CreatePasswordReset(reset *models.PasswordReset) error
+	ConsumePasswordReset(tokenHash string, passwordHash string) (int, error)
+	SetUserTOTPSecret(userID int, encryptedSecret string) error
+	EnableUserTOTP(userID int, recoveryCodeHashes []string) error
+	UseTOTPStep(userID int, step int64) (bool, error)
+	UseRecoveryCode(userID int, codeHash string) (bool, error)
+	CreateAPIKey(apiKey *models.APIKey) error
+	GetAPIKeys() ([]*models.APIKey, error)
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+	RevokeAPIKey(id int) error
+	TouchAPIKey(id int) error
+	CreateImportJob(job *models.ImportJob) error
+	GetImportJob(id int) (*models.ImportJob, error)
+	ClaimImportJob(lease time.Duration, maxAttempts int) (*models.ImportJob, error)
+	RenewImportJobLease(job *models.ImportJob, lease time.Duration) (bool, error)
+	UpdateImportJobProgress(job *models.ImportJob) error
+	AddImportJobError(jobID int, jobError models.ImportJobError) error
+	FinishImportJob(job *models.ImportJob) error
+	CancelImportJob(id int) (bool, error)
+	SaveRefreshToken(token *models.RefreshToken) error
+	GetRefreshToken(jti string) (*models.RefreshToken, error)
+	RotateRefreshToken(oldJTI string, token *models.RefreshToken) error
+	RevokeRefreshTokenFamily(familyID string) error
+	RevokeUserRefreshTokens(userID int) error
 }
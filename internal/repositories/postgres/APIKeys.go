@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+func (r *PostgresRepository) CreateAPIKey(apiKey *models.APIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into api_keys
+			(user_id, name, prefix, key_hash,
+			role, expires_at, created_at)
+		values
+			($1, $2, $3, $4, $5, $6, $7)
+		returning id
+	`
+
+	apiKey.CreatedAt = time.Now()
+
+	row := r.DB.QueryRowContext(ctx, query,
+		apiKey.UserID,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.KeyHash,
+		apiKey.Role,
+		apiKey.ExpiresAt,
+		apiKey.CreatedAt,
+	)
+
+	err := row.Scan(
+		&apiKey.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) GetAPIKeys() ([]*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			k.id, k.user_id, k.name, k.prefix, k.key_hash, k.role, u.role,
+			k.expires_at, k.last_used_at, k.revoked_at, k.created_at
+		from
+			api_keys k
+			join users u on (u.id = k.user_id)
+		order by
+			k.created_at desc
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	apiKeys := []*models.APIKey{}
+
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		apiKeys = append(apiKeys, apiKey)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (r *PostgresRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			k.id, k.user_id, k.name, k.prefix, k.key_hash, k.role, u.role,
+			k.expires_at, k.last_used_at, k.revoked_at, k.created_at
+		from
+			api_keys k
+			join users u on (u.id = k.user_id)
+		where
+			k.key_hash = $1
+	`
+
+	return scanAPIKey(r.DB.QueryRowContext(ctx, query, keyHash))
+}
+
+func (r *PostgresRepository) RevokeAPIKey(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	result, err := r.DB.ExecContext(ctx, `update api_keys set revoked_at = coalesce(revoked_at, $1) where id = $2`, time.Now(), id)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) TouchAPIKey(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	_, err := r.DB.ExecContext(ctx, `update api_keys set last_used_at = $1 where id = $2`, time.Now(), id)
+
+	return err
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (*models.APIKey, error) {
+	var apiKey models.APIKey
+
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.UserID,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.KeyHash,
+		&apiKey.Role,
+		&apiKey.OwnerRole,
+		&apiKey.ExpiresAt,
+		&apiKey.LastUsedAt,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
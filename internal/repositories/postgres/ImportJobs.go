@@ -0,0 +1,285 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+func (r *PostgresRepository) CreateImportJob(job *models.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into import_jobs
+			(status, params, created_by, created_at)
+		values
+			($1, $2, nullif($3, 0), $4)
+		returning id
+	`
+
+	job.Status = models.ImportJobQueued
+	job.CreatedAt = time.Now()
+
+	row := r.DB.QueryRowContext(ctx, query,
+		job.Status,
+		[]byte(job.Params),
+		job.CreatedBy,
+		job.CreatedAt,
+	)
+
+	err := row.Scan(
+		&job.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) GetImportJob(id int) (*models.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			id, status, params, considered, skipped, imported, failed, attempt,
+			coalesce(error, ''), coalesce(created_by, 0), created_at, started_at, finished_at
+		from
+			import_jobs
+		where
+			id = $1
+	`
+
+	job, err := scanImportJob(r.DB.QueryRowContext(ctx, query, id))
+
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		select
+			coalesce(tmdb_id, 0), coalesce(title, ''), reason, attempt
+		from
+			import_job_errors
+		where
+			job_id = $1
+		order by
+			id
+	`
+
+	rows, err := r.DB.QueryContext(ctx, query, id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var jobError models.ImportJobError
+
+		err := rows.Scan(
+			&jobError.TMDBID,
+			&jobError.Title,
+			&jobError.Reason,
+			&jobError.Attempt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		job.Errors = append(job.Errors, jobError)
+	}
+
+	return job, rows.Err()
+}
+
+func (r *PostgresRepository) ClaimImportJob(lease time.Duration, maxAttempts int) (*models.ImportJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	now := time.Now()
+
+	query := `
+		update import_jobs
+			set status = $1, error = $2, finished_at = $3, lease_expires_at = null
+		where
+			status = $4 and coalesce(lease_expires_at, '-infinity') < $3 and attempt >= $5
+	`
+
+	_, err := r.DB.ExecContext(ctx, query,
+		models.ImportJobFailed,
+		fmt.Sprintf("import job stopped responding %d times", maxAttempts),
+		now,
+		models.ImportJobRunning,
+		maxAttempts,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		update import_jobs
+			set status = $1, started_at = coalesce(started_at, $2), lease_expires_at = $3, attempt = attempt + 1
+		where id = (
+			select id from import_jobs
+			where status = $4 or (status = $1 and coalesce(lease_expires_at, '-infinity') < $2 and attempt < $5)
+			order by id
+			limit 1
+			for update skip locked
+		)
+		returning
+			id, status, params, considered, skipped, imported, failed, attempt,
+			coalesce(error, ''), coalesce(created_by, 0), created_at, started_at, finished_at
+	`
+
+	return scanImportJob(r.DB.QueryRowContext(ctx, query, models.ImportJobRunning, now, now.Add(lease), models.ImportJobQueued, maxAttempts))
+}
+
+func (r *PostgresRepository) RenewImportJobLease(job *models.ImportJob, lease time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update import_jobs
+			set lease_expires_at = $1
+		where
+			id = $2 and attempt = $3 and status = $4
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, time.Now().Add(lease), job.ID, job.Attempt, models.ImportJobRunning)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	return affected > 0, err
+}
+
+func (r *PostgresRepository) UpdateImportJobProgress(job *models.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update import_jobs
+			set considered = $1, skipped = $2, imported = $3, failed = $4
+		where
+			id = $5 and attempt = $6
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, job.Considered, job.Skipped, job.Imported, job.Failed, job.ID, job.Attempt)
+
+	return err
+}
+
+func (r *PostgresRepository) AddImportJobError(jobID int, jobError models.ImportJobError) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into import_job_errors
+			(job_id, tmdb_id, title, reason, attempt, created_at)
+		values
+			($1, nullif($2, 0), $3, $4, $5, $6)
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, jobID, jobError.TMDBID, jobError.Title, jobError.Reason, jobError.Attempt, time.Now())
+
+	return err
+}
+
+func (r *PostgresRepository) FinishImportJob(job *models.ImportJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update import_jobs
+			set status = $1, error = nullif($2, ''), considered = $3, skipped = $4,
+			imported = $5, failed = $6, finished_at = $7, lease_expires_at = null
+		where
+			id = $8 and status = $9 and attempt = $10
+	`
+
+	now := time.Now()
+
+	_, err := r.DB.ExecContext(ctx, query,
+		job.Status,
+		job.Error,
+		job.Considered,
+		job.Skipped,
+		job.Imported,
+		job.Failed,
+		now,
+		job.ID,
+		models.ImportJobRunning,
+		job.Attempt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	job.FinishedAt = &now
+
+	return nil
+}
+
+func (r *PostgresRepository) CancelImportJob(id int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update import_jobs
+			set status = $1, finished_at = $2
+		where
+			id = $3 and status in ($4, $5)
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, models.ImportJobCancelled, time.Now(), id, models.ImportJobQueued, models.ImportJobRunning)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	return affected > 0, err
+}
+
+func scanImportJob(row interface{ Scan(dest ...any) error }) (*models.ImportJob, error) {
+	var job models.ImportJob
+	var params []byte
+
+	err := row.Scan(
+		&job.ID,
+		&job.Status,
+		&params,
+		&job.Considered,
+		&job.Skipped,
+		&job.Imported,
+		&job.Failed,
+		&job.Attempt,
+		&job.Error,
+		&job.CreatedBy,
+		&job.CreatedAt,
+		&job.StartedAt,
+		&job.FinishedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	job.Params = params
+	job.Errors = []models.ImportJobError{}
+
+	return &job, nil
+}
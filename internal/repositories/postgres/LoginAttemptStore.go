@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"backend/internal/throttle"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type LoginAttemptStore struct {
+	DB *sql.DB
+}
+
+func (s *LoginAttemptStore) Get(key string) (throttle.Attempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			failures, last_failure_at, locked_until
+		from
+			login_attempts
+		where
+			key = $1
+	`
+
+	attempt, err := scanLoginAttempt(s.DB.QueryRowContext(ctx, query, key))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return throttle.Attempt{}, nil
+	}
+
+	return attempt, err
+}
+
+func (s *LoginAttemptStore) Increment(key string, now time.Time) (throttle.Attempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into login_attempts
+			(key, failures, last_failure_at)
+		values
+			($1, 1, $2)
+		on conflict (key) do update
+			set failures = login_attempts.failures + 1, last_failure_at = excluded.last_failure_at
+		returning
+			failures, last_failure_at, locked_until
+	`
+
+	return scanLoginAttempt(s.DB.QueryRowContext(ctx, query, key, now.UTC()))
+}
+
+func (s *LoginAttemptStore) Lock(key string, until time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	_, err := s.DB.ExecContext(ctx, `update login_attempts set locked_until = $1 where key = $2`, until.UTC(), key)
+
+	return err
+}
+
+func (s *LoginAttemptStore) Reset(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	_, err := s.DB.ExecContext(ctx, `delete from login_attempts where key = $1`, key)
+
+	return err
+}
+
+func scanLoginAttempt(row *sql.Row) (throttle.Attempt, error) {
+	var attempt throttle.Attempt
+	var lockedUntil sql.NullTime
+
+	err := row.Scan(
+		&attempt.Failures,
+		&attempt.LastFailure,
+		&lockedUntil,
+	)
+
+	if err != nil {
+		return throttle.Attempt{}, err
+	}
+
+	attempt.LockedUntil = lockedUntil.Time
+
+	return attempt, nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"backend/internal/throttle"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := sql.Open("pgx", dsn)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { db.Close() })
+
+	err = db.Ping()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func TestLoginAttemptStore(t *testing.T) {
+	store := &LoginAttemptStore{DB: openTestDB(t)}
+	key := "test:" + t.Name()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	_ = store.Reset(key)
+	t.Cleanup(func() { _ = store.Reset(key) })
+
+	attempt, err := store.Get(key)
+
+	if err != nil || attempt != (throttle.Attempt{}) {
+		t.Fatalf("expected an empty attempt, got %+v, %v", attempt, err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		attempt, err = store.Increment(key, now)
+
+		if err != nil || attempt.Failures != i || !attempt.LastFailure.Equal(now) {
+			t.Fatalf("increment %d: got %+v, %v", i, attempt, err)
+		}
+	}
+
+	err = store.Lock(key, now.Add(time.Minute))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempt, err = store.Get(key)
+
+	if err != nil || attempt.Failures != 2 || !attempt.LockedUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the lock to keep the failures, got %+v, %v", attempt, err)
+	}
+
+	err = store.Reset(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempt, err = store.Get(key)
+
+	if err != nil || attempt != (throttle.Attempt{}) {
+		t.Errorf("expected reset to clear the attempt, got %+v, %v", attempt, err)
+	}
+}
+
+func TestLoginAttemptStoreWithLimiter(t *testing.T) {
+	store := &LoginAttemptStore{DB: openTestDB(t)}
+	key := "test:" + t.Name()
+
+	_ = store.Reset(key)
+	t.Cleanup(func() { _ = store.Reset(key) })
+
+	limiter := &throttle.Limiter{
+		Store:  store,
+		Policy: throttle.Policy{MaxFailures: 2, LockoutDuration: time.Minute, ResetAfter: time.Hour},
+	}
+
+	for i := 0; i < 2; i++ {
+		err := limiter.Fail(key)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wait, err := limiter.RetryAfter(key)
+
+	if err != nil || wait <= 0 || wait > time.Minute {
+		t.Errorf("expected the key to be locked for up to a minute, got %s, %v", wait, err)
+	}
+}
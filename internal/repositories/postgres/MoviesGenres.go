@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"database/sql"
+	"time"
+)
+
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func syncMovieGenres(ctx context.Context, db queryer, movie *models.Movie) error {
+	_, err := db.ExecContext(ctx, `delete from movies_genres where movie_id = $1`, movie.ID)
+
+	if err != nil {
+		return err
+	}
+
+	query := `
+		insert into movies_genres
+			(movie_id, genre_id)
+		values
+			($1, $2)
+	`
+
+	for _, genreID := range movie.GenresArray {
+		_, err := db.ExecContext(ctx, query, movie.ID, genreID)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return attachGenres(ctx, db, movie)
+}
+
+func attachGenres(ctx context.Context, db queryer, movies ...*models.Movie) error {
+	if len(movies) == 0 {
+		return nil
+	}
+
+	moviesByID := make(map[int]*models.Movie, len(movies))
+	movieIDs := make([]int, 0, len(movies))
+
+	for _, movie := range movies {
+		movie.Genres = []*models.Genre{}
+		movie.GenresArray = []int{}
+		moviesByID[movie.ID] = movie
+		movieIDs = append(movieIDs, movie.ID)
+	}
+
+	query := `
+		select
+			mg.movie_id, g.id, g.genre, coalesce(g.tmdb_id, 0), g.created_at, g.updated_at
+		from
+			movies_genres mg
+			join genres g on (g.id = mg.genre_id)
+		where
+			mg.movie_id = any($1)
+		order by
+			g.genre
+	`
+
+	rows, err := db.QueryContext(ctx, query, movieIDs)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var movieID int
+		var genre models.Genre
+
+		err := rows.Scan(
+			&movieID,
+			&genre.ID,
+			&genre.Name,
+			&genre.TMDBID,
+			&genre.CreatedAt,
+			&genre.UpdatedAt,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		movie := moviesByID[movieID]
+		movie.Genres = append(movie.Genres, &genre)
+		movie.GenresArray = append(movie.GenresArray, genre.ID)
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresRepository) UpsertTMDBGenres(genres []*models.Genre) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = upsertTMDBGenres(ctx, tx, genres)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func upsertTMDBGenres(ctx context.Context, db queryer, genres []*models.Genre) error {
+	claimQuery := `
+		update genres
+			set tmdb_id = $1, updated_at = $2
+		where
+			tmdb_id is null and lower(genre) = lower($3)
+			and not exists (select 1 from genres where tmdb_id = $1)
+	`
+
+	upsertQuery := `
+		insert into genres
+			(genre, tmdb_id, created_at, updated_at)
+		values
+			($1, $2, $3, $3)
+		on conflict (tmdb_id) do update
+			set tmdb_id = excluded.tmdb_id
+		returning id
+	`
+
+	for _, genre := range genres {
+		_, err := db.ExecContext(ctx, claimQuery, genre.TMDBID, time.Now(), genre.Name)
+
+		if err != nil {
+			return err
+		}
+
+		err = db.QueryRowContext(ctx, upsertQuery, genre.Name, genre.TMDBID, time.Now()).Scan(&genre.ID)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func upsertNewGenres(ctx context.Context, db queryer, movie *models.Movie) error {
+	var newGenres []*models.Genre
+
+	for _, genre := range movie.Genres {
+		if genre.ID == 0 && genre.TMDBID > 0 {
+			newGenres = append(newGenres, genre)
+		}
+	}
+
+	if len(newGenres) == 0 {
+		return nil
+	}
+
+	err := upsertTMDBGenres(ctx, db, newGenres)
+
+	if err != nil {
+		return err
+	}
+
+	movie.GenresArray = []int{}
+
+	for _, genre := range movie.Genres {
+		movie.GenresArray = append(movie.GenresArray, genre.ID)
+	}
+
+	return nil
+}
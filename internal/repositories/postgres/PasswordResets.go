@@ -0,0 +1,87 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+func (r *PostgresRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into password_resets
+			(user_id, token_hash, expires_at, created_at)
+		values
+			($1, $2, $3, $4)
+		returning id
+	`
+
+	row := r.DB.QueryRowContext(ctx, query,
+		reset.UserID,
+		reset.TokenHash,
+		reset.ExpiresAt,
+		time.Now(),
+	)
+
+	err := row.Scan(
+		&reset.ID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) ConsumePasswordReset(tokenHash string, passwordHash string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		update password_resets
+			set used_at = $1
+		where
+			token_hash = $2 and used_at is null and expires_at > $1
+		returning user_id
+	`
+
+	var userID int
+
+	err = tx.QueryRowContext(ctx, query, time.Now(), tokenHash).Scan(&userID)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, repositories.ErrInvalidResetToken
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `update password_resets set used_at = $1 where user_id = $2 and used_at is null`, time.Now(), userID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `update users set password = $1, updated_at = $2 where id = $3`, passwordHash, time.Now(), userID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, tx.Commit()
+}
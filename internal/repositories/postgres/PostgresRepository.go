@@ -3,10 +3,17 @@ package postgres
 import (
 	"backend/internal/dtos"
 	"backend/internal/models"
+	"backend/internal/repositories"
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
 	"strings"
 	"time"
+
+	"github.com/jackc/pgconn"
 )
 
 type PostgresRepository struct {
@@ -15,6 +22,18 @@ type PostgresRepository struct {
 
 const connectionTimeout = time.Second * 3
 
+const batchTimeout = time.Minute
+
+const streamTimeout = time.Minute * 10
+
+const (
+	headlineStart     = "\x02"
+	headlineStop      = "\x03"
+	headlineSelectors = `StartSel="` + headlineStart + `", StopSel="` + headlineStop + `"`
+)
+
+var headlineMarks = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
 func (r *PostgresRepository) GetConnection() *sql.DB {
 	return r.DB
 }
@@ -23,17 +42,59 @@ func (r *PostgresRepository) SaveMovie(movie *models.Movie) error {
 	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
 	defer cancel()
 
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = insertMovie(ctx, tx, movie)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) SaveMovies(movies []*models.Movie) error {
+	ctx, cancel := context.WithTimeout(context.Background(), batchTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	for i, movie := range movies {
+		err = insertMovie(ctx, tx, movie)
+
+		if err != nil {
+			return &repositories.BatchError{Index: i, Err: err}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func insertMovie(ctx context.Context, db queryer, movie *models.Movie) error {
 	query := `
 		insert into movies
 			(title, release_date, runtime,
 			mpaa_rating, rating, vote_count,
-			description, image, created_at, updated_at)
+			description, image, created_at, updated_at,
+			created_by, updated_by, tmdb_id)
 		values
-			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, nullif($11, 0), nullif($12, 0), nullif($13, 0))
 		returning id
 	`
 
-	row := r.DB.QueryRowContext(ctx, query,
+	row := db.QueryRowContext(ctx, query,
 		movie.Title,
 		movie.ReleaseDate,
 		movie.Duration,
@@ -44,67 +105,111 @@ func (r *PostgresRepository) SaveMovie(movie *models.Movie) error {
 		movie.Image,
 		time.Now(),
 		time.Now(),
+		movie.CreatedBy,
+		movie.UpdatedBy,
+		movie.TMDBID,
 	)
 
 	err := row.Scan(
 		&movie.ID,
 	)
 
+	if err != nil {
+		return translateMovieError(err)
+	}
+
+	err = upsertNewGenres(ctx, db, movie)
+
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return syncMovieGenres(ctx, db, movie)
 }
 
 func (r *PostgresRepository) UpdateMovie(movie *models.Movie) error {
 	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
 	defer cancel()
 
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
 	query := `
 		update movies
 			set title = $1, release_date = $2,
 			runtime = $3, mpaa_rating = $4, description = $5,
-			image = $6, updated_at=$7
+			image = $6, updated_at=$7, updated_by = nullif($8, 0),
+			rating = $9, vote_count = $10, tmdb_id = coalesce(nullif($11, 0), tmdb_id)
 		where
-			id = $8
+			id = $12
 	`
 
-	r.DB.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		movie.Title,
 		movie.ReleaseDate,
 		movie.Duration,
-		movie.Rating,
+		movie.MPAARating,
 		movie.Description,
 		movie.Image,
 		time.Now(),
+		movie.UpdatedBy,
+		movie.Rating,
+		movie.VoteCount,
+		movie.TMDBID,
 		movie.ID,
 	)
 
-	return nil
+	if err != nil {
+		return translateMovieError(err)
+	}
+
+	if movie.GenresArray != nil {
+		err = syncMovieGenres(ctx, tx, movie)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func translateMovieError(err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "movies_tmdb_id_key" {
+		return repositories.ErrDuplicateTMDBID
+	}
+
+	return err
 }
 
 func (r *PostgresRepository) GetAllMovies() ([]*models.Movie, error) {
 	return r.GetMovies()
 }
 
-func (r *PostgresRepository) GetMovies(searchColumns ...dtos.SearchColumn) ([]*models.Movie, error) {
+func (r *PostgresRepository) GetMovies(filters ...repositories.Filter) ([]*models.Movie, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
 	defer cancel()
 
-	var whereConditions []string
+	where, args, err := buildWhere(filters, movieColumns, nil)
 
-	for _, searchColumn := range searchColumns {
-		whereConditions = append(whereConditions, searchColumn.Column+searchColumn.Operator+"'"+searchColumn.Value+"'")
+	if err != nil {
+		return nil, err
 	}
 
-	where := strings.Join(whereConditions, " and ")
-
 	queryStart := `
 		select
 			id, title, release_date, runtime,
 			mpaa_rating, coalesce(rating, 0.0), coalesce(vote_count, 0), description,
-			coalesce(image, ''), created_at, updated_at
+			coalesce(image, ''), created_at, updated_at,
+			coalesce(created_by, 0), coalesce(updated_by, 0), coalesce(tmdb_id, 0),
+			coalesce(poster_hash, ''), coalesce(poster_source, '')
 		from
 			movies
 			`
@@ -112,7 +217,272 @@ func (r *PostgresRepository) GetMovies(searchColumns ...dtos.SearchColumn) ([]*m
 
 	query := queryStart + " " + where + " " + queryEnd
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	return r.queryMovies(ctx, query, args...)
+}
+
+func (r *PostgresRepository) ListMovies(movieQuery repositories.MovieQuery) ([]*models.Movie, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	where, args, err := buildMovieWhere(movieQuery, nil)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int
+
+	err = r.DB.QueryRowContext(ctx, "select count(*) from movies "+where, args...).Scan(&total)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	orderBy, err := buildOrderBy(movieQuery.SortBy, movieQuery.Descending, sortableMovieColumns)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		select
+			id, title, release_date, runtime,
+			mpaa_rating, coalesce(rating, 0.0), coalesce(vote_count, 0), description,
+			coalesce(image, ''), created_at, updated_at,
+			coalesce(created_by, 0), coalesce(updated_by, 0), coalesce(tmdb_id, 0),
+			coalesce(poster_hash, ''), coalesce(poster_source, '')
+		from
+			movies
+		` + where + `
+		` + orderBy
+
+	if movieQuery.Limit > 0 {
+		args = append(args, movieQuery.Limit, movieQuery.Offset)
+		query += fmt.Sprintf(" limit $%d offset $%d", len(args)-1, len(args))
+	}
+
+	movies, err := r.queryMovies(ctx, query, args...)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return movies, total, nil
+}
+
+func (r *PostgresRepository) StreamMovies(movieQuery repositories.MovieQuery, each func(movie *models.Movie) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), streamTimeout)
+	defer cancel()
+
+	where, args, err := buildMovieWhere(movieQuery, nil)
+
+	if err != nil {
+		return err
+	}
+
+	orderBy, err := buildOrderBy(movieQuery.SortBy, movieQuery.Descending, sortableMovieColumns)
+
+	if err != nil {
+		return err
+	}
+
+	query := `
+		select
+			id, title, release_date, runtime,
+			mpaa_rating, coalesce(rating, 0.0), coalesce(vote_count, 0), description,
+			coalesce(image, ''), created_at, updated_at,
+			coalesce(created_by, 0), coalesce(updated_by, 0), coalesce(tmdb_id, 0),
+			coalesce(poster_hash, ''), coalesce(poster_source, ''),
+			coalesce((
+				select
+					json_agg(json_build_object('id', g.id, 'name', g.genre, 'tmdb_id', coalesce(g.tmdb_id, 0)) order by g.genre)
+				from
+					movies_genres mg
+					join genres g on (g.id = mg.genre_id)
+				where
+					mg.movie_id = movies.id
+			), '[]')
+		from
+			movies
+		` + where + `
+		` + orderBy
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var movie models.Movie
+		var genres []byte
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.ReleaseDate,
+			&movie.Duration,
+			&movie.MPAARating,
+			&movie.Rating,
+			&movie.VoteCount,
+			&movie.Description,
+			&movie.Image,
+			&movie.CreatedAt,
+			&movie.UpdatedAt,
+			&movie.CreatedBy,
+			&movie.UpdatedBy,
+			&movie.TMDBID,
+			&movie.PosterHash,
+			&movie.PosterSource,
+			&genres,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		err = json.Unmarshal(genres, &movie.Genres)
+
+		if err != nil {
+			return err
+		}
+
+		movie.GenresArray = []int{}
+
+		for _, genre := range movie.Genres {
+			movie.GenresArray = append(movie.GenresArray, genre.ID)
+		}
+
+		err = each(&movie)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *PostgresRepository) SearchMovies(search string, movieQuery repositories.MovieQuery) ([]*dtos.MovieSearchResult, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	tsQuery := prefixTSQuery(search)
+
+	if tsQuery == "" {
+		return nil, 0, nil
+	}
+
+	args := []any{tsQuery}
+
+	where, args, err := buildMovieWhere(movieQuery, args)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	where = appendCondition(where, "search_vector @@ to_tsquery('english', $1)")
+
+	var total int
+
+	err = r.DB.QueryRowContext(ctx, "select count(*) from movies "+where, args...).Scan(&total)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		select
+			id, title, release_date, runtime,
+			mpaa_rating, coalesce(rating, 0.0), coalesce(vote_count, 0), description,
+			coalesce(image, ''), created_at, updated_at,
+			coalesce(created_by, 0), coalesce(updated_by, 0), coalesce(tmdb_id, 0),
+			coalesce(poster_hash, ''), coalesce(poster_source, ''),
+			ts_rank(search_vector, to_tsquery('english', $1)) as rank,
+			ts_headline('english', coalesce(title, ''), to_tsquery('english', $1), '` + headlineSelectors + `, HighlightAll=true'),
+			ts_headline('english', coalesce(description, ''), to_tsquery('english', $1), '` + headlineSelectors + `, MaxWords=35, MinWords=15')
+		from
+			movies
+		` + where + `
+		order by
+			rank desc, title, id`
+
+	if movieQuery.Limit > 0 {
+		args = append(args, movieQuery.Limit, movieQuery.Offset)
+		query += fmt.Sprintf(" limit $%d offset $%d", len(args)-1, len(args))
+	}
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer rows.Close()
+
+	var results []*dtos.MovieSearchResult
+	var movies []*models.Movie
+
+	for rows.Next() {
+		var movie models.Movie
+		var result dtos.MovieSearchResult
+
+		err := rows.Scan(
+			&movie.ID,
+			&movie.Title,
+			&movie.ReleaseDate,
+			&movie.Duration,
+			&movie.MPAARating,
+			&movie.Rating,
+			&movie.VoteCount,
+			&movie.Description,
+			&movie.Image,
+			&movie.CreatedAt,
+			&movie.UpdatedAt,
+			&movie.CreatedBy,
+			&movie.UpdatedBy,
+			&movie.TMDBID,
+			&movie.PosterHash,
+			&movie.PosterSource,
+			&result.Rank,
+			&result.TitleHighlight,
+			&result.Snippet,
+		)
+
+		if err != nil {
+			return nil, 0, err
+		}
+
+		result.TitleHighlight = escapeHeadline(result.TitleHighlight)
+		result.Snippet = escapeHeadline(result.Snippet)
+		result.Movie = &movie
+		results = append(results, &result)
+		movies = append(movies, &movie)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = attachGenres(ctx, r.DB, movies...)
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return results, total, nil
+}
+
+func escapeHeadline(headline string) string {
+	return headlineMarks.Replace(html.EscapeString(headline))
+}
+
+func (r *PostgresRepository) queryMovies(ctx context.Context, query string, args ...any) ([]*models.Movie, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -137,6 +507,11 @@ func (r *PostgresRepository) GetMovies(searchColumns ...dtos.SearchColumn) ([]*m
 			&movie.Image,
 			&movie.CreatedAt,
 			&movie.UpdatedAt,
+			&movie.CreatedBy,
+			&movie.UpdatedBy,
+			&movie.TMDBID,
+			&movie.PosterHash,
+			&movie.PosterSource,
 		)
 
 		if err != nil {
@@ -146,6 +521,18 @@ func (r *PostgresRepository) GetMovies(searchColumns ...dtos.SearchColumn) ([]*m
 		movies = append(movies, &movie)
 	}
 
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachGenres(ctx, r.DB, movies...)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return movies, nil
 }
 
@@ -157,7 +544,9 @@ func (r *PostgresRepository) GetMovieByID(id int) (*models.Movie, error) {
 		select
 			id, title, release_date, runtime,
 			mpaa_rating, coalesce(rating, 0.0), coalesce(vote_count, 0), description,
-			coalesce(image, ''), created_at, updated_at
+			coalesce(image, ''), created_at, updated_at,
+			coalesce(created_by, 0), coalesce(updated_by, 0), coalesce(tmdb_id, 0),
+			coalesce(poster_hash, ''), coalesce(poster_source, '')
 		from
 			movies
 		where
@@ -182,12 +571,23 @@ func (r *PostgresRepository) GetMovieByID(id int) (*models.Movie, error) {
 		&movie.Image,
 		&movie.CreatedAt,
 		&movie.UpdatedAt,
+		&movie.CreatedBy,
+		&movie.UpdatedBy,
+		&movie.TMDBID,
+		&movie.PosterHash,
+		&movie.PosterSource,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
+	err = attachGenres(ctx, r.DB, &movie)
+
+	if err != nil {
+		return nil, err
+	}
+
 	return &movie, nil
 }
 
@@ -206,21 +606,19 @@ func (r *PostgresRepository) DeleteMovie(id int) error {
 	return nil
 }
 
-func (r *PostgresRepository) GetGenres(searchColumns ...dtos.SearchColumn) ([]*models.Genre, error) {
+func (r *PostgresRepository) GetGenres(filters ...repositories.Filter) ([]*models.Genre, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
 	defer cancel()
 
-	var whereConditions []string
+	where, args, err := buildWhere(filters, genreColumns, nil)
 
-	for _, searchColumn := range searchColumns {
-		whereConditions = append(whereConditions, searchColumn.Column+searchColumn.Operator+"'"+searchColumn.Value+"'")
+	if err != nil {
+		return nil, err
 	}
 
-	where := strings.Join(whereConditions, " and ")
-
 	queryStart := `
 		select
-			id, genre, created_at, updated_at
+			id, genre, coalesce(tmdb_id, 0), created_at, updated_at
 		from
 			genres
 			`
@@ -228,7 +626,7 @@ func (r *PostgresRepository) GetGenres(searchColumns ...dtos.SearchColumn) ([]*m
 
 	query := queryStart + " " + where + " " + queryEnd
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	rows, err := r.DB.QueryContext(ctx, query, args...)
 
 	if err != nil {
 		return nil, err
@@ -244,6 +642,7 @@ func (r *PostgresRepository) GetGenres(searchColumns ...dtos.SearchColumn) ([]*m
 		err := rows.Scan(
 			&genre.ID,
 			&genre.Name,
+			&genre.TMDBID,
 			&genre.CreatedAt,
 			&genre.UpdatedAt,
 		)
@@ -255,6 +654,12 @@ func (r *PostgresRepository) GetGenres(searchColumns ...dtos.SearchColumn) ([]*m
 		genres = append(genres, &genre)
 	}
 
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
 	return genres, nil
 }
 
@@ -265,11 +670,12 @@ func (r *PostgresRepository) GetUserByEmail(email string) (*models.User, error)
 	query := `
 		select
 			id, first_name, last_name, email,
-			password, created_at, updated_at
+			password, role, coalesce(totp_secret, ''), totp_enabled,
+			created_at, updated_at
 		from
 			users
 		where
-			email = $1
+			lower(email) = lower($1)
 	`
 
 	row := r.DB.QueryRowContext(ctx, query, email)
@@ -282,6 +688,9 @@ func (r *PostgresRepository) GetUserByEmail(email string) (*models.User, error)
 		&user.LastName,
 		&user.Email,
 		&user.Password,
+		&user.Role,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -300,7 +709,8 @@ func (r *PostgresRepository) GetUserByID(id int) (*models.User, error) {
 	query := `
 		select
 			id, first_name, last_name, email,
-			password, created_at, updated_at
+			password, role, coalesce(totp_secret, ''), totp_enabled,
+			created_at, updated_at
 		from
 			users
 		where
@@ -317,6 +727,9 @@ func (r *PostgresRepository) GetUserByID(id int) (*models.User, error) {
 		&user.LastName,
 		&user.Email,
 		&user.Password,
+		&user.Role,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -327,3 +740,19 @@ func (r *PostgresRepository) GetUserByID(id int) (*models.User, error) {
 
 	return &user, nil
 }
+
+func (r *PostgresRepository) SetMoviePoster(id int, posterHash string, posterSource string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update movies
+			set poster_hash = nullif($1, ''), poster_source = nullif($2, ''), updated_at = $3
+		where
+			id = $4
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, posterHash, posterSource, time.Now(), id)
+
+	return err
+}
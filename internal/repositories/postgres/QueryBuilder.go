@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"backend/internal/repositories"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+var movieColumns = map[string]string{
+	"id":           "id",
+	"title":        "title",
+	"release_date": "release_date",
+	"runtime":      "runtime",
+	"mpaa_rating":  "mpaa_rating",
+	"rating":       "rating",
+	"vote_count":   "vote_count",
+	"description":  "description",
+	"image":        "image",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+	"tmdb_id":      "tmdb_id",
+}
+
+var sortableMovieColumns = map[string]string{
+	"title":        "title",
+	"release_date": "release_date",
+	"rating":       "rating",
+	"vote_count":   "vote_count",
+	"created_at":   "created_at",
+}
+
+var genreColumns = map[string]string{
+	"id":         "id",
+	"genre":      "genre",
+	"tmdb_id":    "tmdb_id",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+func buildWhere(filters []repositories.Filter, columns map[string]string, args []any) (string, []any, error) {
+	if len(filters) == 0 {
+		return "", args, nil
+	}
+
+	conditions := make([]string, 0, len(filters))
+
+	placeholder := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	for _, filter := range filters {
+		column, ok := columns[filter.Column]
+
+		if !ok {
+			return "", nil, fmt.Errorf("%w: %q", repositories.ErrUnknownColumn, filter.Column)
+		}
+
+		err := filter.Validate()
+
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch filter.Operator {
+		case repositories.In:
+			placeholders := make([]string, len(filter.Values))
+
+			for i, value := range filter.Values {
+				placeholders[i] = placeholder(value)
+			}
+
+			conditions = append(conditions, fmt.Sprintf("%s in (%s)", column, strings.Join(placeholders, ", ")))
+		case repositories.Between:
+			conditions = append(conditions, fmt.Sprintf("%s between %s and %s", column, placeholder(filter.Values[0]), placeholder(filter.Values[1])))
+		case repositories.IsNull:
+			conditions = append(conditions, column+" is null")
+		default:
+			conditions = append(conditions, fmt.Sprintf("%s %s %s", column, strings.ToLower(string(filter.Operator)), placeholder(filter.Values[0])))
+		}
+	}
+
+	return "where " + strings.Join(conditions, " and "), args, nil
+}
+
+func buildMovieWhere(movieQuery repositories.MovieQuery, args []any) (string, []any, error) {
+	where, args, err := buildWhere(movieQuery.Filters, movieColumns, args)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	if movieQuery.GenreID > 0 {
+		args = append(args, movieQuery.GenreID)
+		where = appendCondition(where, fmt.Sprintf("id in (select movie_id from movies_genres where genre_id = $%d)", len(args)))
+	}
+
+	return where, args, nil
+}
+
+func prefixTSQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, word := range words {
+		words[i] = strings.ToLower(word) + ":*"
+	}
+
+	return strings.Join(words, " & ")
+}
+
+func appendCondition(where string, condition string) string {
+	if where == "" {
+		return "where " + condition
+	}
+
+	return where + " and " + condition
+}
+
+func buildOrderBy(sortBy string, descending bool, columns map[string]string) (string, error) {
+	if sortBy == "" {
+		sortBy = "title"
+	}
+
+	column, ok := columns[sortBy]
+
+	if !ok {
+		return "", fmt.Errorf("%w: %q", repositories.ErrUnknownColumn, sortBy)
+	}
+
+	direction := "asc"
+
+	if descending {
+		direction = "desc"
+	}
+
+	return fmt.Sprintf("order by %s %s nulls last, id %s", column, direction, direction), nil
+}
@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"backend/internal/repositories"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBuildWhere(t *testing.T) {
+	tests := []struct {
+		name      string
+		filters   []repositories.Filter
+		args      []any
+		wantWhere string
+		wantArgs  []any
+		wantErr   error
+	}{
+		{
+			name:      "no filters",
+			wantWhere: "",
+		},
+		{
+			name:      "equal",
+			filters:   []repositories.Filter{repositories.NewFilter("title", repositories.Equal, "Alien")},
+			wantWhere: "where title = $1",
+			wantArgs:  []any{"Alien"},
+		},
+		{
+			name:      "apostrophe is passed as an argument",
+			filters:   []repositories.Filter{repositories.NewFilter("title", repositories.Equal, "Schindler's List")},
+			wantWhere: "where title = $1",
+			wantArgs:  []any{"Schindler's List"},
+		},
+		{
+			name:      "injection attempt is passed as an argument",
+			filters:   []repositories.Filter{repositories.NewFilter("title", repositories.ILike, "'; drop table movies; --")},
+			wantWhere: "where title ilike $1",
+			wantArgs:  []any{"'; drop table movies; --"},
+		},
+		{
+			name: "combined filters",
+			filters: []repositories.Filter{
+				repositories.NewFilter("tmdb_id", repositories.IsNull),
+				repositories.NewFilter("id", repositories.In, 1, 2, 3),
+				repositories.NewFilter("release_date", repositories.Between, "1979-01-01", "1979-12-31"),
+			},
+			wantWhere: "where tmdb_id is null and id in ($1, $2, $3) and release_date between $4 and $5",
+			wantArgs:  []any{1, 2, 3, "1979-01-01", "1979-12-31"},
+		},
+		{
+			name:      "placeholders continue after existing args",
+			filters:   []repositories.Filter{repositories.NewFilter("rating", repositories.GreaterOrEqual, 7)},
+			args:      []any{"alien:*"},
+			wantWhere: "where rating >= $2",
+			wantArgs:  []any{"alien:*", 7},
+		},
+		{
+			name:    "unknown column",
+			filters: []repositories.Filter{repositories.NewFilter("title; drop table movies; --", repositories.Equal, "x")},
+			wantErr: repositories.ErrUnknownColumn,
+		},
+		{
+			name:    "unmapped real column",
+			filters: []repositories.Filter{repositories.NewFilter("search_vector", repositories.Equal, "x")},
+			wantErr: repositories.ErrUnknownColumn,
+		},
+		{
+			name:    "unknown operator",
+			filters: []repositories.Filter{repositories.NewFilter("title", repositories.Operator("= 1 or 1 ="), "x")},
+			wantErr: repositories.ErrUnknownOperator,
+		},
+		{
+			name:    "equal without value",
+			filters: []repositories.Filter{repositories.NewFilter("title", repositories.Equal)},
+			wantErr: repositories.ErrFilterArity,
+		},
+		{
+			name:    "between with one value",
+			filters: []repositories.Filter{repositories.NewFilter("release_date", repositories.Between, "1979-01-01")},
+			wantErr: repositories.ErrFilterArity,
+		},
+		{
+			name:    "in without values",
+			filters: []repositories.Filter{repositories.NewFilter("id", repositories.In)},
+			wantErr: repositories.ErrFilterArity,
+		},
+		{
+			name:    "is null with a value",
+			filters: []repositories.Filter{repositories.NewFilter("tmdb_id", repositories.IsNull, 1)},
+			wantErr: repositories.ErrFilterArity,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			where, args, err := buildWhere(test.filters, movieColumns, test.args)
+
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected error %v, got %v", test.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if where != test.wantWhere {
+				t.Errorf("expected where %q, got %q", test.wantWhere, where)
+			}
+
+			if !reflect.DeepEqual(args, test.wantArgs) {
+				t.Errorf("expected args %#v, got %#v", test.wantArgs, args)
+			}
+		})
+	}
+}
+
+func TestBuildOrderBy(t *testing.T) {
+	tests := []struct {
+		name       string
+		sortBy     string
+		descending bool
+		want       string
+		wantErr    error
+	}{
+		{name: "default", want: "order by title asc nulls last, id asc"},
+		{name: "descending", sortBy: "rating", descending: true, want: "order by rating desc nulls last, id desc"},
+		{name: "unknown column", sortBy: "title; drop table movies; --", wantErr: repositories.ErrUnknownColumn},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			orderBy, err := buildOrderBy(test.sortBy, test.descending, sortableMovieColumns)
+
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("expected error %v, got %v", test.wantErr, err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if orderBy != test.want {
+				t.Errorf("expected %q, got %q", test.want, orderBy)
+			}
+		})
+	}
+}
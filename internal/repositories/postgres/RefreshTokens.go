@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"time"
+)
+
+func (r *PostgresRepository) SaveRefreshToken(token *models.RefreshToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	return insertRefreshToken(ctx, r.DB, token)
+}
+
+func (r *PostgresRepository) GetRefreshToken(jti string) (*models.RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			id, jti, family_id, user_id, expires_at,
+			revoked_at, coalesce(replaced_by, ''), created_at
+		from
+			refresh_tokens
+		where
+			jti = $1
+	`
+
+	row := r.DB.QueryRowContext(ctx, query, jti)
+
+	var token models.RefreshToken
+
+	err := row.Scan(
+		&token.ID,
+		&token.JTI,
+		&token.FamilyID,
+		&token.UserID,
+		&token.ExpiresAt,
+		&token.RevokedAt,
+		&token.ReplacedBy,
+		&token.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *PostgresRepository) RotateRefreshToken(oldJTI string, token *models.RefreshToken) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	query := `
+		update refresh_tokens
+			set revoked_at = $1, replaced_by = $2
+		where
+			jti = $3 and revoked_at is null
+	`
+
+	result, err := tx.ExecContext(ctx, query, time.Now(), token.JTI, oldJTI)
+
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return repositories.ErrRefreshTokenRevoked
+	}
+
+	err = insertRefreshToken(ctx, tx, token)
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) RevokeRefreshTokenFamily(familyID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update refresh_tokens
+			set revoked_at = $1
+		where
+			family_id = $2 and revoked_at is null
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, time.Now(), familyID)
+
+	return err
+}
+
+func (r *PostgresRepository) RevokeUserRefreshTokens(userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update refresh_tokens
+			set revoked_at = $1
+		where
+			user_id = $2 and revoked_at is null
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, time.Now(), userID)
+
+	return err
+}
+
+func insertRefreshToken(ctx context.Context, db queryer, token *models.RefreshToken) error {
+	query := `
+		insert into refresh_tokens
+			(jti, family_id, user_id, expires_at, created_at)
+		values
+			($1, $2, $3, $4, $5)
+		returning id
+	`
+
+	row := db.QueryRowContext(ctx, query,
+		token.JTI,
+		token.FamilyID,
+		token.UserID,
+		token.ExpiresAt,
+		time.Now(),
+	)
+
+	return row.Scan(&token.ID)
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type TMDBCache struct {
+	DB *sql.DB
+}
+
+func (c *TMDBCache) Get(key string) ([]byte, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			value
+		from
+			tmdb_cache
+		where
+			key = $1 and expires_at > $2
+	`
+
+	var value []byte
+
+	err := c.DB.QueryRowContext(ctx, query, key, time.Now().UTC()).Scan(&value)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	return value, true, nil
+}
+
+func (c *TMDBCache) Set(key string, value []byte, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into tmdb_cache
+			(key, value, expires_at)
+		values
+			($1, $2, $3)
+		on conflict (key) do update
+			set value = excluded.value, expires_at = excluded.expires_at
+	`
+
+	_, err := c.DB.ExecContext(ctx, query, key, value, time.Now().UTC().Add(ttl))
+
+	if err != nil {
+		return err
+	}
+
+	_, err = c.DB.ExecContext(ctx, `delete from tmdb_cache where expires_at < $1`, time.Now().UTC().Add(-24*time.Hour))
+
+	return err
+}
+
+func (c *TMDBCache) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	_, err := c.DB.ExecContext(ctx, `delete from tmdb_cache where key = $1`, key)
+
+	return err
+}
@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"time"
+)
+
+func (r *PostgresRepository) SetUserTOTPSecret(userID int, encryptedSecret string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update users
+			set totp_secret = $1, totp_enabled = false,
+			totp_last_step = null, updated_at = $2
+		where
+			id = $3
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, encryptedSecret, time.Now(), userID)
+
+	return err
+}
+
+func (r *PostgresRepository) EnableUserTOTP(userID int, recoveryCodeHashes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `update users set totp_enabled = true, updated_at = $1 where id = $2`, time.Now(), userID)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `delete from recovery_codes where user_id = $1`, userID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, codeHash := range recoveryCodeHashes {
+		_, err = tx.ExecContext(ctx, `insert into recovery_codes (user_id, code_hash, created_at) values ($1, $2, $3)`, userID, codeHash, time.Now())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) UseTOTPStep(userID int, step int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update users
+			set totp_last_step = $1
+		where
+			id = $2 and coalesce(totp_last_step, -1) < $1
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, step, userID)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (r *PostgresRepository) UseRecoveryCode(userID int, codeHash string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update recovery_codes
+			set used_at = $1
+		where
+			user_id = $2 and code_hash = $3 and used_at is null
+	`
+
+	result, err := r.DB.ExecContext(ctx, query, time.Now(), userID, codeHash)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"context"
+	"time"
+)
+
+func (r *PostgresRepository) GetUserByIdentity(issuer string, subject string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		select
+			u.id, u.first_name, u.last_name, u.email,
+			u.password, u.role, coalesce(u.totp_secret, ''), u.totp_enabled,
+			u.created_at, u.updated_at
+		from
+			users u
+			join user_identities ui on ui.user_id = u.id
+		where
+			ui.issuer = $1 and ui.subject = $2
+	`
+
+	row := r.DB.QueryRowContext(ctx, query, issuer, subject)
+
+	var user models.User
+
+	err := row.Scan(
+		&user.ID,
+		&user.FirstName,
+		&user.LastName,
+		&user.Email,
+		&user.Password,
+		&user.Role,
+		&user.TOTPSecret,
+		&user.TOTPEnabled,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *PostgresRepository) LinkUserIdentity(userID int, issuer string, subject string, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into user_identities
+			(user_id, issuer, subject, email, created_at)
+		values
+			($1, $2, $3, $4, $5)
+		on conflict (issuer, subject) do nothing
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, userID, issuer, subject, email, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
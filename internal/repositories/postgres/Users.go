@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgconn"
+)
+
+const uniqueViolation = "23505"
+
+func (r *PostgresRepository) CreateUser(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		insert into users
+			(first_name, last_name, email, password,
+			role, created_at, updated_at)
+		values
+			($1, $2, $3, $4, $5, $6, $7)
+		returning id
+	`
+
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = user.CreatedAt
+
+	row := r.DB.QueryRowContext(ctx, query,
+		user.FirstName,
+		user.LastName,
+		user.Email,
+		user.Password,
+		user.Role,
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+
+	err := row.Scan(
+		&user.ID,
+	)
+
+	if err != nil {
+		return translateUserError(err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) UpdateUser(user *models.User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update users
+			set first_name = $1, last_name = $2,
+			email = $3, updated_at = $4
+		where
+			id = $5
+	`
+
+	user.UpdatedAt = time.Now()
+
+	_, err := r.DB.ExecContext(ctx, query,
+		user.FirstName,
+		user.LastName,
+		user.Email,
+		user.UpdatedAt,
+		user.ID,
+	)
+
+	if err != nil {
+		return translateUserError(err)
+	}
+
+	return nil
+}
+
+func (r *PostgresRepository) UpdateUserPassword(id int, passwordHash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	query := `
+		update users
+			set password = $1, updated_at = $2
+		where
+			id = $3
+	`
+
+	_, err := r.DB.ExecContext(ctx, query, passwordHash, time.Now(), id)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func translateUserError(err error) error {
+	var pgErr *pgconn.PgError
+
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.ConstraintName == "users_email_key" {
+		return repositories.ErrDuplicateEmail
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+type Box struct {
+	Key []byte
+}
+
+func (b *Box) Seal(plainText string) (string, error) {
+	gcm, err := b.gcm()
+
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	_, err = rand.Read(nonce)
+
+	if err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plainText), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (b *Box) Open(cipherText string) (string, error) {
+	gcm, err := b.gcm()
+
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(cipherText)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("sealed value is too short")
+	}
+
+	plainText, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(plainText), nil
+}
+
+func (b *Box) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(b.Key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
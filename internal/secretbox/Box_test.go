@@ -0,0 +1,75 @@
+package secretbox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	box := &Box{Key: bytes.Repeat([]byte{7}, 32)}
+
+	sealed, err := box.Seal("JBSWY3DPEHPK3PXP")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sealed == "JBSWY3DPEHPK3PXP" {
+		t.Fatal("sealed value must not be the plain text")
+	}
+
+	again, err := box.Seal("JBSWY3DPEHPK3PXP")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if again == sealed {
+		t.Error("sealing twice must use a fresh nonce")
+	}
+
+	opened, err := box.Open(sealed)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if opened != "JBSWY3DPEHPK3PXP" {
+		t.Errorf("expected the original plain text, got %q", opened)
+	}
+}
+
+func TestOpenRejects(t *testing.T) {
+	box := &Box{Key: bytes.Repeat([]byte{7}, 32)}
+
+	sealed, err := box.Seal("secret")
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tampered := []byte(sealed)
+	tampered[len(tampered)/2] ^= 1
+
+	tests := []struct {
+		name       string
+		box        *Box
+		cipherText string
+	}{
+		{name: "wrong key", box: &Box{Key: bytes.Repeat([]byte{8}, 32)}, cipherText: sealed},
+		{name: "tampered", box: box, cipherText: string(tampered)},
+		{name: "too short", box: box, cipherText: "AAAA"},
+		{name: "not base64", box: box, cipherText: "not base64!"},
+		{name: "invalid key size", box: &Box{Key: []byte("short")}, cipherText: sealed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.box.Open(test.cipherText)
+
+			if err == nil {
+				t.Error("expected an error, got nil")
+			}
+		})
+	}
+}
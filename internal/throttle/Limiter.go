@@ -0,0 +1,108 @@
+package throttle
+
+import "time"
+
+type Policy struct {
+	MaxFailures     int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutDuration time.Duration
+	ResetAfter      time.Duration
+}
+
+type Limiter struct {
+	Store  Store
+	Policy Policy
+
+	clock func() time.Time
+}
+
+func (l *Limiter) RetryAfter(key string) (time.Duration, error) {
+	attempt, err := l.Store.Get(key)
+
+	if err != nil {
+		return 0, err
+	}
+
+	now := l.now()
+
+	if attempt.LockedUntil.After(now) {
+		return attempt.LockedUntil.Sub(now), nil
+	}
+
+	if attempt.Failures == 0 || l.isStale(attempt, now) {
+		return 0, nil
+	}
+
+	blockedUntil := attempt.LastFailure.Add(l.backoff(attempt.Failures))
+
+	if blockedUntil.After(now) {
+		return blockedUntil.Sub(now), nil
+	}
+
+	return 0, nil
+}
+
+func (l *Limiter) Fail(key string) error {
+	now := l.now()
+
+	attempt, err := l.Store.Get(key)
+
+	if err != nil {
+		return err
+	}
+
+	if attempt.Failures > 0 && l.isStale(attempt, now) {
+		err = l.Store.Reset(key)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	attempt, err = l.Store.Increment(key, now)
+
+	if err != nil {
+		return err
+	}
+
+	if l.Policy.MaxFailures > 0 && attempt.Failures >= l.Policy.MaxFailures {
+		return l.Store.Lock(key, now.Add(l.Policy.LockoutDuration))
+	}
+
+	return nil
+}
+
+func (l *Limiter) Reset(key string) error {
+	return l.Store.Reset(key)
+}
+
+func (l *Limiter) backoff(failures int) time.Duration {
+	delay := l.Policy.BaseDelay
+
+	for i := 1; i < failures && delay < l.Policy.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, l.Policy.MaxDelay)
+}
+
+func (l *Limiter) isStale(attempt Attempt, now time.Time) bool {
+	if attempt.LockedUntil.After(now) {
+		return false
+	}
+
+	if !attempt.LockedUntil.IsZero() {
+		return true
+	}
+
+	return l.Policy.ResetAfter > 0 && now.Sub(attempt.LastFailure) > l.Policy.ResetAfter
+}
+
+func (l *Limiter) now() time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+
+	return time.Now()
+}
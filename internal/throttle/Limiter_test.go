@@ -0,0 +1,161 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+type step struct {
+	fail    int
+	advance time.Duration
+	reset   bool
+	want    time.Duration
+}
+
+func TestLimiter(t *testing.T) {
+	policy := Policy{
+		MaxFailures:     3,
+		BaseDelay:       time.Second,
+		MaxDelay:        4 * time.Second,
+		LockoutDuration: 10 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+
+	tests := []struct {
+		name   string
+		policy Policy
+		steps  []step
+	}{
+		{
+			name:   "no failures",
+			policy: policy,
+			steps:  []step{{want: 0}},
+		},
+		{
+			name:   "backoff doubles with each failure",
+			policy: policy,
+			steps: []step{
+				{fail: 1, want: time.Second},
+				{fail: 1, want: 2 * time.Second},
+				{advance: 1500 * time.Millisecond, want: 500 * time.Millisecond},
+				{advance: time.Second, want: 0},
+			},
+		},
+		{
+			name:   "backoff is capped",
+			policy: Policy{BaseDelay: time.Second, MaxDelay: 4 * time.Second},
+			steps: []step{
+				{fail: 10, want: 4 * time.Second},
+			},
+		},
+		{
+			name:   "locks after max failures",
+			policy: policy,
+			steps: []step{
+				{fail: 3, want: 10 * time.Minute},
+				{advance: 4 * time.Minute, want: 6 * time.Minute},
+				{fail: 1, want: 10 * time.Minute},
+			},
+		},
+		{
+			name:   "expired lock starts a new window",
+			policy: policy,
+			steps: []step{
+				{fail: 3, want: 10 * time.Minute},
+				{advance: 10*time.Minute + time.Second, want: 0},
+				{fail: 1, want: time.Second},
+				{fail: 1, want: 2 * time.Second},
+				{fail: 1, want: 10 * time.Minute},
+			},
+		},
+		{
+			name:   "failures older than the window are forgotten",
+			policy: policy,
+			steps: []step{
+				{fail: 2, want: 2 * time.Second},
+				{advance: time.Hour + time.Second, want: 0},
+				{fail: 1, want: time.Second},
+				{fail: 1, want: 2 * time.Second},
+			},
+		},
+		{
+			name:   "failures inside the window accumulate",
+			policy: policy,
+			steps: []step{
+				{fail: 2, want: 2 * time.Second},
+				{advance: 30 * time.Minute, want: 0},
+				{fail: 1, want: 10 * time.Minute},
+			},
+		},
+		{
+			name:   "reset unlocks",
+			policy: policy,
+			steps: []step{
+				{fail: 3, want: 10 * time.Minute},
+				{reset: true, want: 0},
+				{fail: 1, want: time.Second},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+			limiter := &Limiter{
+				Store:  &MemoryStore{TTL: 24 * time.Hour},
+				Policy: test.policy,
+				clock:  func() time.Time { return now },
+			}
+
+			for i, step := range test.steps {
+				now = now.Add(step.advance)
+
+				if step.reset {
+					err := limiter.Reset("account:ada@example.com")
+
+					if err != nil {
+						t.Fatalf("step %d: unexpected error: %v", i, err)
+					}
+				}
+
+				for j := 0; j < step.fail; j++ {
+					err := limiter.Fail("account:ada@example.com")
+
+					if err != nil {
+						t.Fatalf("step %d: unexpected error: %v", i, err)
+					}
+				}
+
+				got, err := limiter.RetryAfter("account:ada@example.com")
+
+				if err != nil {
+					t.Fatalf("step %d: unexpected error: %v", i, err)
+				}
+
+				if got != step.want {
+					t.Errorf("step %d: expected retry after %s, got %s", i, step.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLimiterKeysAreIndependent(t *testing.T) {
+	limiter := &Limiter{
+		Store:  &MemoryStore{},
+		Policy: Policy{MaxFailures: 1, LockoutDuration: time.Minute},
+	}
+
+	err := limiter.Fail("ip:192.0.2.1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wait, err := limiter.RetryAfter("ip:192.0.2.2")
+
+	if err != nil || wait != 0 {
+		t.Errorf("expected another key to be unaffected, got %s, %v", wait, err)
+	}
+}
@@ -0,0 +1,79 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+const memoryStorePruneInterval = time.Minute
+
+type MemoryStore struct {
+	TTL time.Duration
+
+	mu        sync.Mutex
+	attempts  map[string]Attempt
+	lastPrune time.Time
+}
+
+func (s *MemoryStore) Get(key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) Increment(key string, now time.Time) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attempts == nil {
+		s.attempts = map[string]Attempt{}
+	}
+
+	s.prune(now)
+
+	attempt := s.attempts[key]
+	attempt.Failures++
+	attempt.LastFailure = now
+	s.attempts[key] = attempt
+
+	return attempt, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.attempts == nil {
+		s.attempts = map[string]Attempt{}
+	}
+
+	attempt := s.attempts[key]
+	attempt.LockedUntil = until
+	s.attempts[key] = attempt
+
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
+
+func (s *MemoryStore) prune(now time.Time) {
+	if s.TTL <= 0 || now.Sub(s.lastPrune) < memoryStorePruneInterval {
+		return
+	}
+
+	s.lastPrune = now
+
+	for key, attempt := range s.attempts {
+		if now.Sub(attempt.LastFailure) > s.TTL && !attempt.LockedUntil.After(now) {
+			delete(s.attempts, key)
+		}
+	}
+}
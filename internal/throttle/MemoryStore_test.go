@@ -0,0 +1,78 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := &MemoryStore{TTL: time.Hour}
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	attempt, err := store.Get("key")
+
+	if err != nil || attempt != (Attempt{}) {
+		t.Fatalf("expected an empty attempt, got %+v, %v", attempt, err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		attempt, err = store.Increment("key", now)
+
+		if err != nil || attempt.Failures != i || !attempt.LastFailure.Equal(now) {
+			t.Fatalf("increment %d: got %+v, %v", i, attempt, err)
+		}
+	}
+
+	err = store.Lock("key", now.Add(time.Minute))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempt, _ = store.Get("key")
+
+	if attempt.Failures != 2 || !attempt.LockedUntil.Equal(now.Add(time.Minute)) {
+		t.Errorf("expected the lock to keep the failures, got %+v", attempt)
+	}
+
+	err = store.Reset("key")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attempt, _ = store.Get("key")
+
+	if attempt != (Attempt{}) {
+		t.Errorf("expected reset to clear the attempt, got %+v", attempt)
+	}
+}
+
+func TestMemoryStorePrunes(t *testing.T) {
+	store := &MemoryStore{TTL: time.Hour}
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	_, _ = store.Increment("stale", now)
+	_, _ = store.Increment("locked", now)
+	_ = store.Lock("locked", now.Add(3*time.Hour))
+
+	later := now.Add(2 * time.Hour)
+
+	_, _ = store.Increment("fresh", later)
+
+	stale, _ := store.Get("stale")
+	locked, _ := store.Get("locked")
+	fresh, _ := store.Get("fresh")
+
+	if stale.Failures != 0 {
+		t.Errorf("expected the stale attempt to be pruned, got %+v", stale)
+	}
+
+	if locked.Failures != 1 {
+		t.Errorf("expected a locked attempt to be kept, got %+v", locked)
+	}
+
+	if fresh.Failures != 1 {
+		t.Errorf("expected the fresh attempt to be kept, got %+v", fresh)
+	}
+}
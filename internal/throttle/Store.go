@@ -0,0 +1,16 @@
+package throttle
+
+import "time"
+
+type Attempt struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+type Store interface {
+	Get(key string) (Attempt, error)
+	Increment(key string, now time.Time) (Attempt, error)
+	Lock(key string, until time.Time) error
+	Reset(key string) error
+}
@@ -0,0 +1,139 @@
+package tmdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const memoryCachePruneInterval = time.Minute
+
+type Cache interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+}
+
+type MovieInvalidator interface {
+	InvalidateMovie(id int) error
+}
+
+type MemoryCache struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	lastPrune time.Time
+}
+
+type memoryCacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+func (c *MemoryCache) Get(key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false, nil
+	}
+
+	return entry.value, true, nil
+}
+
+func (c *MemoryCache) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = map[string]memoryCacheEntry{}
+	}
+
+	now := time.Now()
+
+	if now.Sub(c.lastPrune) > memoryCachePruneInterval {
+		for entryKey, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, entryKey)
+			}
+		}
+
+		c.lastPrune = now
+	}
+
+	c.entries[key] = memoryCacheEntry{value: value, expiresAt: now.Add(ttl)}
+
+	return nil
+}
+
+func (c *MemoryCache) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+
+	return nil
+}
+
+type CachedClient struct {
+	Client Client
+	Cache  Cache
+	TTL    time.Duration
+}
+
+func (c *CachedClient) SearchMovies(query string, page int) (*SearchResponse, error) {
+	key := fmt.Sprintf("search:%d:%s", page, strings.ToLower(strings.TrimSpace(query)))
+
+	return cached(c, key, func() (*SearchResponse, error) {
+		return c.Client.SearchMovies(query, page)
+	})
+}
+
+func (c *CachedClient) GetMovie(id int) (*MovieDetails, error) {
+	return cached(c, movieCacheKey(id), func() (*MovieDetails, error) {
+		return c.Client.GetMovie(id)
+	})
+}
+
+func (c *CachedClient) InvalidateMovie(id int) error {
+	return c.Cache.Delete(movieCacheKey(id))
+}
+
+func (c *CachedClient) GetGenres() ([]Genre, error) {
+	return cached(c, "genres", c.Client.GetGenres)
+}
+
+func (c *CachedClient) GetConfiguration() (*Configuration, error) {
+	return cached(c, "configuration", c.Client.GetConfiguration)
+}
+
+func movieCacheKey(id int) string {
+	return fmt.Sprintf("movie:%d:release_dates", id)
+}
+
+func cached[T any](c *CachedClient, key string, fetch func() (T, error)) (T, error) {
+	var value T
+
+	data, ok, err := c.Cache.Get(key)
+
+	if err == nil && ok && json.Unmarshal(data, &value) == nil {
+		return value, nil
+	}
+
+	value, err = fetch()
+
+	if err != nil {
+		return value, err
+	}
+
+	data, err = json.Marshal(value)
+
+	if err == nil {
+		_ = c.Cache.Set(key, data, c.TTL)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,176 @@
+package tmdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://api.themoviedb.org/3"
+	DefaultTimeout = 10 * time.Second
+)
+
+type Client interface {
+	SearchMovies(query string, page int) (*SearchResponse, error)
+	GetMovie(id int) (*MovieDetails, error)
+	GetGenres() ([]Genre, error)
+	GetConfiguration() (*Configuration, error)
+}
+
+type HTTPClient struct {
+	BaseURL string
+	APIKey  string
+	HTTP    *http.Client
+}
+
+func NewClient(baseURL string, apiKey string, timeout time.Duration) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &HTTPClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		APIKey:  apiKey,
+		HTTP:    &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *HTTPClient) SearchMovies(query string, page int) (*SearchResponse, error) {
+	params := url.Values{}
+	params.Set("query", query)
+
+	if page > 0 {
+		params.Set("page", strconv.Itoa(page))
+	}
+
+	var searchResponse SearchResponse
+
+	err := c.get("/search/movie", params, &searchResponse)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &searchResponse, nil
+}
+
+func (c *HTTPClient) GetMovie(id int) (*MovieDetails, error) {
+	var movieDetails MovieDetails
+
+	params := url.Values{}
+	params.Set("append_to_response", "release_dates")
+
+	err := c.get(fmt.Sprintf("/movie/%d", id), params, &movieDetails)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &movieDetails, nil
+}
+
+func (c *HTTPClient) GetGenres() ([]Genre, error) {
+	var genreList struct {
+		Genres []Genre `json:"genres"`
+	}
+
+	err := c.get("/genre/movie/list", url.Values{}, &genreList)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return genreList.Genres, nil
+}
+
+func (c *HTTPClient) GetConfiguration() (*Configuration, error) {
+	var configuration Configuration
+
+	err := c.get("/configuration", url.Values{}, &configuration)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &configuration, nil
+}
+
+func (c *HTTPClient) get(path string, params url.Values, target any) error {
+	params.Set("api_key", c.APIKey)
+
+	request, err := http.NewRequest("GET", c.BaseURL+path+"?"+params.Encode(), nil)
+
+	if err != nil {
+		return err
+	}
+
+	request.Header.Add("Accept", "application/json")
+
+	response, err := c.HTTP.Do(request)
+
+	if err != nil {
+		return redactURL(err, c.BaseURL+path)
+	}
+
+	defer response.Body.Close()
+
+	bodyBytes, err := io.ReadAll(io.LimitReader(response.Body, 10<<20))
+
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return newAPIError(response, bodyBytes)
+	}
+
+	err = json.Unmarshal(bodyBytes, target)
+
+	if err != nil {
+		return fmt.Errorf("tmdb: decoding %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func redactURL(err error, redacted string) error {
+	var urlError *url.Error
+
+	if !errors.As(err, &urlError) {
+		return err
+	}
+
+	return &url.Error{Op: urlError.Op, URL: redacted, Err: urlError.Err}
+}
+
+func newAPIError(response *http.Response, bodyBytes []byte) *APIError {
+	var errorBody struct {
+		StatusMessage string `json:"status_message"`
+	}
+
+	_ = json.Unmarshal(bodyBytes, &errorBody)
+
+	apiError := &APIError{
+		StatusCode:    response.StatusCode,
+		StatusMessage: errorBody.StatusMessage,
+	}
+
+	seconds, err := strconv.Atoi(response.Header.Get("Retry-After"))
+
+	if err == nil && seconds > 0 {
+		apiError.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return apiError
+}
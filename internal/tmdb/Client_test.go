@@ -0,0 +1,260 @@
+package tmdb_test
+
+import (
+	"backend/internal/tmdb"
+	"backend/internal/tmdb/tmdbtest"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var alien = tmdb.MovieDetails{
+	ID:          348,
+	Title:       "Alien",
+	ReleaseDate: "1979-05-25",
+	Runtime:     117,
+	Genres:      []tmdb.Genre{{ID: 27, Name: "Horror"}, {ID: 878, Name: "Science Fiction"}},
+}
+
+func newServer(t *testing.T) *tmdbtest.Server {
+	t.Helper()
+
+	server := tmdbtest.NewServer()
+	server.AddMovie(alien)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		client     func(server *tmdbtest.Server) *tmdb.HTTPClient
+		id         int
+		statusCode int
+		wantErr    error
+	}{
+		{
+			name: "unauthorized",
+			client: func(server *tmdbtest.Server) *tmdb.HTTPClient {
+				return tmdb.NewClient(server.URL, "wrong-key", 5*time.Second)
+			},
+			id:      alien.ID,
+			wantErr: tmdb.ErrUnauthorized,
+		},
+		{
+			name:    "not found",
+			id:      1,
+			wantErr: tmdb.ErrNotFound,
+		},
+		{
+			name:       "rate limited",
+			id:         alien.ID,
+			statusCode: http.StatusTooManyRequests,
+			wantErr:    tmdb.ErrRateLimited,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newServer(t)
+			client := server.Client()
+
+			if test.client != nil {
+				client = test.client(server)
+			}
+
+			if test.statusCode != 0 {
+				server.FailNext(test.statusCode)
+			}
+
+			_, err := client.GetMovie(test.id)
+
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+
+			var apiError *tmdb.APIError
+
+			if !errors.As(err, &apiError) {
+				t.Fatalf("expected an *tmdb.APIError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestClientRedactsAPIKey(t *testing.T) {
+	server := newServer(t)
+	server.Close()
+
+	_, err := server.Client().GetMovie(alien.ID)
+
+	if err == nil {
+		t.Fatal("expected an error from a closed server")
+	}
+
+	if strings.Contains(err.Error(), tmdbtest.APIKey) {
+		t.Errorf("error exposes the API key: %v", err)
+	}
+
+	var urlError *url.Error
+
+	if !errors.As(err, &urlError) {
+		t.Errorf("expected a *url.Error, got %T", err)
+	}
+}
+
+func TestClientRetryAfter(t *testing.T) {
+	server := newServer(t)
+	server.FailNext(http.StatusTooManyRequests, 3*time.Second)
+
+	_, err := server.Client().GetMovie(alien.ID)
+
+	var apiError *tmdb.APIError
+
+	if !errors.As(err, &apiError) {
+		t.Fatalf("expected an *tmdb.APIError, got %v", err)
+	}
+
+	if apiError.RetryAfter != 3*time.Second {
+		t.Errorf("expected Retry-After of 3s, got %s", apiError.RetryAfter)
+	}
+}
+
+func TestRetryingClient(t *testing.T) {
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		server := newServer(t)
+		server.FailNext(http.StatusServiceUnavailable)
+		server.FailNext(http.StatusBadGateway)
+
+		client := &tmdb.RetryingClient{Client: server.Client(), MaxRetries: 3, BaseDelay: time.Millisecond}
+
+		movie, err := client.GetMovie(alien.ID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if movie.Title != alien.Title {
+			t.Errorf("expected %q, got %q", alien.Title, movie.Title)
+		}
+
+		if server.Requests() != 3 {
+			t.Errorf("expected 3 requests, got %d", server.Requests())
+		}
+	})
+
+	t.Run("waits for Retry-After", func(t *testing.T) {
+		server := newServer(t)
+		server.FailNext(http.StatusTooManyRequests, time.Second)
+
+		client := &tmdb.RetryingClient{Client: server.Client(), MaxRetries: 1, BaseDelay: time.Millisecond}
+
+		start := time.Now()
+
+		_, err := client.GetMovie(alien.ID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if elapsed := time.Since(start); elapsed < time.Second {
+			t.Errorf("expected to wait at least 1s before retrying, waited %s", elapsed)
+		}
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		server := newServer(t)
+
+		for i := 0; i < 3; i++ {
+			server.FailNext(http.StatusServiceUnavailable)
+		}
+
+		client := &tmdb.RetryingClient{Client: server.Client(), MaxRetries: 2, BaseDelay: time.Millisecond}
+
+		_, err := client.GetMovie(alien.ID)
+
+		var apiError *tmdb.APIError
+
+		if !errors.As(err, &apiError) || apiError.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("expected a 503 error, got %v", err)
+		}
+
+		if server.Requests() != 3 {
+			t.Errorf("expected 3 requests, got %d", server.Requests())
+		}
+	})
+
+	t.Run("does not retry permanent errors", func(t *testing.T) {
+		server := newServer(t)
+
+		client := &tmdb.RetryingClient{Client: server.Client(), MaxRetries: 3, BaseDelay: time.Millisecond}
+
+		_, err := client.GetMovie(1)
+
+		if !errors.Is(err, tmdb.ErrNotFound) {
+			t.Fatalf("expected %v, got %v", tmdb.ErrNotFound, err)
+		}
+
+		if server.Requests() != 1 {
+			t.Errorf("expected 1 request, got %d", server.Requests())
+		}
+	})
+}
+
+func TestCachedClient(t *testing.T) {
+	server := newServer(t)
+
+	client := &tmdb.CachedClient{Client: server.Client(), Cache: &tmdb.MemoryCache{}, TTL: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		movie, err := client.GetMovie(alien.ID)
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if movie.Title != alien.Title || len(movie.Genres) != len(alien.Genres) {
+			t.Errorf("unexpected movie %+v", movie)
+		}
+	}
+
+	if server.Requests() != 1 {
+		t.Fatalf("expected the second lookup to be served from the cache, got %d requests", server.Requests())
+	}
+
+	err := client.InvalidateMovie(alien.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetMovie(alien.ID)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if server.Requests() != 2 {
+		t.Errorf("expected a lookup after invalidation to miss the cache, got %d requests", server.Requests())
+	}
+
+	_, err = client.GetMovie(1)
+
+	if !errors.Is(err, tmdb.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", tmdb.ErrNotFound, err)
+	}
+
+	_, err = client.GetMovie(1)
+
+	if !errors.Is(err, tmdb.ErrNotFound) {
+		t.Fatalf("expected %v, got %v", tmdb.ErrNotFound, err)
+	}
+
+	if server.Requests() != 4 {
+		t.Errorf("expected errors not to be cached, got %d requests", server.Requests())
+	}
+}
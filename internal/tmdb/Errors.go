@@ -0,0 +1,41 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrUnauthorized = errors.New("tmdb: invalid API key")
+	ErrNotFound     = errors.New("tmdb: resource not found")
+	ErrRateLimited  = errors.New("tmdb: rate limit exceeded")
+)
+
+type APIError struct {
+	StatusCode    int
+	StatusMessage string
+	RetryAfter    time.Duration
+}
+
+func (e *APIError) Error() string {
+	if e.StatusMessage != "" {
+		return fmt.Sprintf("tmdb: %d %s", e.StatusCode, e.StatusMessage)
+	}
+
+	return fmt.Sprintf("tmdb: unexpected status %d", e.StatusCode)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+
+	return false
+}
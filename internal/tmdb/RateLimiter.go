@@ -0,0 +1,79 @@
+package tmdb
+
+import (
+	"sync"
+	"time"
+)
+
+type RateLimiter struct {
+	Rate  float64
+	Burst int
+
+	mu          sync.Mutex
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+}
+
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &RateLimiter{
+		Rate:   rate,
+		Burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (l *RateLimiter) Wait() {
+	for {
+		delay := l.reserve(time.Now())
+
+		if delay <= 0 {
+			return
+		}
+
+		time.Sleep(delay)
+	}
+}
+
+func (l *RateLimiter) Pause(duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	until := time.Now().Add(duration)
+
+	if until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+func (l *RateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	if l.Rate <= 0 {
+		return 0
+	}
+
+	l.tokens += now.Sub(l.last).Seconds() * l.Rate
+	l.last = now
+
+	if l.tokens > float64(l.Burst) {
+		l.tokens = float64(l.Burst)
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+
+	return time.Duration((1 - l.tokens) / l.Rate * float64(time.Second))
+}
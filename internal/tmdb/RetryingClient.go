@@ -0,0 +1,116 @@
+package tmdb
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+type RetryingClient struct {
+	Client     Client
+	Limiter    *RateLimiter
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+func (c *RetryingClient) SearchMovies(query string, page int) (*SearchResponse, error) {
+	var searchResponse *SearchResponse
+
+	err := c.do(func() (err error) {
+		searchResponse, err = c.Client.SearchMovies(query, page)
+		return err
+	})
+
+	return searchResponse, err
+}
+
+func (c *RetryingClient) GetMovie(id int) (*MovieDetails, error) {
+	var movieDetails *MovieDetails
+
+	err := c.do(func() (err error) {
+		movieDetails, err = c.Client.GetMovie(id)
+		return err
+	})
+
+	return movieDetails, err
+}
+
+func (c *RetryingClient) GetGenres() ([]Genre, error) {
+	var genres []Genre
+
+	err := c.do(func() (err error) {
+		genres, err = c.Client.GetGenres()
+		return err
+	})
+
+	return genres, err
+}
+
+func (c *RetryingClient) GetConfiguration() (*Configuration, error) {
+	var configuration *Configuration
+
+	err := c.do(func() (err error) {
+		configuration, err = c.Client.GetConfiguration()
+		return err
+	})
+
+	return configuration, err
+}
+
+func (c *RetryingClient) do(call func() error) error {
+	for attempt := 0; ; attempt++ {
+		if c.Limiter != nil {
+			c.Limiter.Wait()
+		}
+
+		err := call()
+
+		if err == nil || !isTransient(err) || attempt >= c.MaxRetries {
+			return err
+		}
+
+		delay := c.backoff(attempt)
+
+		var apiError *APIError
+
+		if errors.As(err, &apiError) && apiError.RetryAfter > 0 {
+			delay = apiError.RetryAfter
+
+			if c.Limiter != nil {
+				c.Limiter.Pause(delay)
+				delay = 0
+			}
+		}
+
+		time.Sleep(delay)
+	}
+}
+
+func (c *RetryingClient) backoff(attempt int) time.Duration {
+	delay := c.BaseDelay << attempt
+
+	if delay <= 0 || (c.MaxDelay > 0 && delay > c.MaxDelay) {
+		delay = c.MaxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func isTransient(err error) bool {
+	var apiError *APIError
+
+	if errors.As(err, &apiError) {
+		return apiError.StatusCode == http.StatusTooManyRequests || apiError.StatusCode >= http.StatusInternalServerError
+	}
+
+	var urlError *url.Error
+
+	return errors.As(err, &urlError)
+}
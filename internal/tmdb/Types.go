@@ -0,0 +1,87 @@
+package tmdb
+
+const theatricalRelease = 3
+
+type SearchResult struct {
+	ID          int     `json:"id"`
+	Title       string  `json:"title"`
+	ReleaseDate string  `json:"release_date"`
+	VoteCount   int     `json:"vote_count"`
+	VoteAverage float32 `json:"vote_average"`
+	Overview    string  `json:"overview"`
+	PosterPath  string  `json:"poster_path"`
+	GenreIDs    []int   `json:"genre_ids"`
+}
+
+type SearchResponse struct {
+	Page         int            `json:"page"`
+	TotalPages   int            `json:"total_pages"`
+	TotalResults int            `json:"total_results"`
+	Results      []SearchResult `json:"results"`
+}
+
+type Genre struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type MovieDetails struct {
+	ID           int     `json:"id"`
+	Title        string  `json:"title"`
+	ReleaseDate  string  `json:"release_date"`
+	Runtime      int     `json:"runtime"`
+	VoteCount    int     `json:"vote_count"`
+	VoteAverage  float32 `json:"vote_average"`
+	Overview     string  `json:"overview"`
+	PosterPath   string  `json:"poster_path"`
+	Genres       []Genre `json:"genres"`
+	ReleaseDates struct {
+		Results []CountryReleaseDates `json:"results"`
+	} `json:"release_dates"`
+}
+
+type CountryReleaseDates struct {
+	Country      string        `json:"iso_3166_1"`
+	ReleaseDates []ReleaseDate `json:"release_dates"`
+}
+
+type ReleaseDate struct {
+	Certification string `json:"certification"`
+	ReleaseDate   string `json:"release_date"`
+	Type          int    `json:"type"`
+}
+
+func (d *MovieDetails) Certification(country string) string {
+	certification := ""
+
+	for _, countryReleaseDates := range d.ReleaseDates.Results {
+		if countryReleaseDates.Country != country {
+			continue
+		}
+
+		for _, releaseDate := range countryReleaseDates.ReleaseDates {
+			if releaseDate.Certification == "" {
+				continue
+			}
+
+			if releaseDate.Type == theatricalRelease {
+				return releaseDate.Certification
+			}
+
+			if certification == "" {
+				certification = releaseDate.Certification
+			}
+		}
+	}
+
+	return certification
+}
+
+type Configuration struct {
+	Images struct {
+		BaseURL       string   `json:"base_url"`
+		SecureBaseURL string   `json:"secure_base_url"`
+		PosterSizes   []string `json:"poster_sizes"`
+		BackdropSizes []string `json:"backdrop_sizes"`
+	} `json:"images"`
+}
@@ -0,0 +1,266 @@
+package tmdbtest
+
+import (
+	"backend/internal/tmdb"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const APIKey = "tmdbtest-key"
+
+type Server struct {
+	*httptest.Server
+	PageSize      int
+	Configuration tmdb.Configuration
+
+	mu       sync.Mutex
+	movies   map[int]tmdb.MovieDetails
+	genres   []tmdb.Genre
+	failures []failure
+	requests int
+}
+
+type failure struct {
+	statusCode int
+	retryAfter time.Duration
+}
+
+func NewServer() *Server {
+	server := &Server{
+		PageSize: 20,
+		movies:   map[int]tmdb.MovieDetails{},
+	}
+
+	server.Configuration.Images.BaseURL = "http://image.tmdb.org/t/p/"
+	server.Configuration.Images.SecureBaseURL = "https://image.tmdb.org/t/p/"
+	server.Configuration.Images.PosterSizes = []string{"w92", "w154", "w185", "w342", "w500", "w780", "original"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/movie", server.search)
+	mux.HandleFunc("/movie/", server.movie)
+	mux.HandleFunc("/genre/movie/list", server.genreList)
+	mux.HandleFunc("/configuration", server.configuration)
+
+	server.Server = httptest.NewServer(server.authenticate(mux))
+
+	return server
+}
+
+func (s *Server) Client() *tmdb.HTTPClient {
+	return tmdb.NewClient(s.URL, APIKey, 5*time.Second)
+}
+
+func (s *Server) AddMovie(movie tmdb.MovieDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.movies[movie.ID] = movie
+
+	for _, genre := range movie.Genres {
+		if !containsGenre(s.genres, genre.ID) {
+			s.genres = append(s.genres, genre)
+		}
+	}
+}
+
+func (s *Server) AddGenre(genre tmdb.Genre) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !containsGenre(s.genres, genre.ID) {
+		s.genres = append(s.genres, genre)
+	}
+}
+
+func (s *Server) FailNext(statusCode int, retryAfter ...time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	next := failure{statusCode: statusCode}
+
+	if len(retryAfter) > 0 {
+		next.retryAfter = retryAfter[0]
+	}
+
+	s.failures = append(s.failures, next)
+}
+
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+
+		var pending *failure
+
+		if len(s.failures) > 0 {
+			pending = &s.failures[0]
+			s.failures = s.failures[1:]
+		}
+
+		s.mu.Unlock()
+
+		if pending != nil {
+			if pending.retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(pending.retryAfter.Seconds())))
+			}
+
+			writeError(w, pending.statusCode, http.StatusText(pending.statusCode))
+			return
+		}
+
+		if r.URL.Query().Get("api_key") != APIKey {
+			writeError(w, http.StatusUnauthorized, "Invalid API key: You must be granted a valid key.")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(r.URL.Query().Get("query"))
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+
+	if page < 1 {
+		page = 1
+	}
+
+	s.mu.Lock()
+
+	var matches []tmdb.SearchResult
+
+	for _, movie := range s.movies {
+		if matchesQuery(movie.Title, query) {
+			matches = append(matches, searchResult(movie))
+		}
+	}
+
+	s.mu.Unlock()
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].ID < matches[j].ID
+	})
+
+	searchResponse := tmdb.SearchResponse{
+		Page:         page,
+		TotalResults: len(matches),
+		TotalPages:   (len(matches) + s.PageSize - 1) / s.PageSize,
+		Results:      []tmdb.SearchResult{},
+	}
+
+	start := (page - 1) * s.PageSize
+
+	if start < len(matches) {
+		end := start + s.PageSize
+
+		if end > len(matches) {
+			end = len(matches)
+		}
+
+		searchResponse.Results = matches[start:end]
+	}
+
+	writeJSON(w, searchResponse)
+}
+
+func (s *Server) movie(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/movie/"))
+
+	if err != nil {
+		writeError(w, http.StatusNotFound, "The resource you requested could not be found.")
+		return
+	}
+
+	s.mu.Lock()
+	movie, ok := s.movies[id]
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "The resource you requested could not be found.")
+		return
+	}
+
+	writeJSON(w, movie)
+}
+
+func (s *Server) genreList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	genres := append([]tmdb.Genre{}, s.genres...)
+	s.mu.Unlock()
+
+	writeJSON(w, map[string][]tmdb.Genre{"genres": genres})
+}
+
+func (s *Server) configuration(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, s.Configuration)
+}
+
+func searchResult(movie tmdb.MovieDetails) tmdb.SearchResult {
+	result := tmdb.SearchResult{
+		ID:          movie.ID,
+		Title:       movie.Title,
+		ReleaseDate: movie.ReleaseDate,
+		VoteCount:   movie.VoteCount,
+		VoteAverage: movie.VoteAverage,
+		Overview:    movie.Overview,
+		PosterPath:  movie.PosterPath,
+		GenreIDs:    []int{},
+	}
+
+	for _, genre := range movie.Genres {
+		result.GenreIDs = append(result.GenreIDs, genre.ID)
+	}
+
+	return result
+}
+
+func matchesQuery(title string, query string) bool {
+	title = strings.ToLower(title)
+
+	for _, word := range strings.Fields(query) {
+		if !strings.Contains(title, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsGenre(genres []tmdb.Genre, id int) bool {
+	for _, genre := range genres {
+		if genre.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"success":        false,
+		"status_code":    statusCode,
+		"status_message": message,
+	})
+}
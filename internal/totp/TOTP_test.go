@@ -0,0 +1,109 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238(t *testing.T) {
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		code, err := Code(rfcSecret, Step(time.Unix(test.unix, 0)))
+
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if code != test.want {
+			t.Errorf("at %d expected %s, got %s", test.unix, test.want, code)
+		}
+	}
+}
+
+func TestCodeAcceptsLowercaseSecret(t *testing.T) {
+	code, err := Code("gezdgnbvgy3tqojqgezdgnbvgy3tqojq", 1)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if code != "287082" {
+		t.Errorf("expected 287082, got %s", code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := Step(now)
+
+	previous, _ := Code(rfcSecret, step-1)
+	current, _ := Code(rfcSecret, step)
+	next, _ := Code(rfcSecret, step+1)
+	tooOld, _ := Code(rfcSecret, step-2)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: current, wantStep: step, wantOK: true},
+		{name: "previous step within skew", code: previous, wantStep: step - 1, wantOK: true},
+		{name: "next step within skew", code: next, wantStep: step + 1, wantOK: true},
+		{name: "outside skew", code: tooOld},
+		{name: "wrong code", code: "000000"},
+		{name: "empty code", code: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotStep, ok := Validate(rfcSecret, test.code, now, 1)
+
+			if ok != test.wantOK || gotStep != test.wantStep {
+				t.Errorf("expected (%d, %v), got (%d, %v)", test.wantStep, test.wantOK, gotStep, ok)
+			}
+		})
+	}
+}
+
+func TestValidateReportsTheSameStepForAReusedCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, _ := Code(rfcSecret, Step(now))
+
+	used := map[int64]bool{}
+
+	for i, at := range []time.Time{now, now.Add(10 * time.Second), now.Add(Period * time.Second)} {
+		step, ok := Validate(rfcSecret, code, at, 1)
+
+		if !ok {
+			t.Fatalf("attempt %d: expected the code to be valid within the skew", i)
+		}
+
+		if i > 0 && !used[step] {
+			t.Fatalf("attempt %d: reused code matched a new step %d, reuse could not be detected", i, step)
+		}
+
+		used[step] = true
+	}
+}
+
+func TestValidateRejectsInvalidSecret(t *testing.T) {
+	_, ok := Validate("not base32!", "123456", time.Now(), 1)
+
+	if ok {
+		t.Error("expected an invalid secret to be rejected")
+	}
+}
@@ -0,0 +1,249 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const maxCellLength = 32767
+
+const contentTypesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>
+</Types>`
+
+const rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+
+const workbookRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`
+
+const workbookXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="%s" sheetId="1" r:id="rId1"/></sheets>
+</workbook>`
+
+const stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="3"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="14" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+</styleSheet>`
+
+const sheetStartXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>
+<sheetData>`
+
+const sheetEndXML = `</sheetData>
+</worksheet>`
+
+const (
+	styleHeader = 1
+	styleDate   = 2
+)
+
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+type Writer struct {
+	zip   *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+func NewWriter(w io.Writer, sheetName string) (*Writer, error) {
+	archive := zip.NewWriter(w)
+
+	var escapedName xmlText
+
+	escapedName.write(sheetName)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypesXML},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", fmt.Sprintf(workbookXML, string(escapedName))},
+		{"xl/_rels/workbook.xml.rels", workbookRelsXML},
+		{"xl/styles.xml", stylesXML},
+	}
+
+	for _, part := range parts {
+		partWriter, err := archive.Create(part.name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = io.WriteString(partWriter, part.content)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sheetWriter, err := archive.Create("xl/worksheets/sheet1.xml")
+
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &Writer{
+		zip:   archive,
+		sheet: bufio.NewWriter(sheetWriter),
+	}
+
+	_, err = writer.sheet.WriteString(sheetStartXML)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return writer, nil
+}
+
+func (w *Writer) WriteHeader(names ...string) error {
+	values := make([]any, len(names))
+
+	for i, name := range names {
+		values[i] = name
+	}
+
+	return w.writeRow(styleHeader, values)
+}
+
+func (w *Writer) WriteRow(values ...any) error {
+	return w.writeRow(0, values)
+}
+
+func (w *Writer) Flush() error {
+	err := w.sheet.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	return w.zip.Flush()
+}
+
+func (w *Writer) Close() error {
+	_, err := w.sheet.WriteString(sheetEndXML)
+
+	if err != nil {
+		return err
+	}
+
+	err = w.sheet.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	return w.zip.Close()
+}
+
+func (w *Writer) writeRow(style int, values []any) error {
+	w.rows++
+
+	var row xmlText
+
+	row = append(row, fmt.Sprintf(`<row r="%d">`, w.rows)...)
+
+	for i, value := range values {
+		ref := columnName(i) + strconv.Itoa(w.rows)
+
+		switch v := value.(type) {
+		case nil:
+			continue
+		case int:
+			row.number(ref, style, strconv.Itoa(v))
+		case int64:
+			row.number(ref, style, strconv.FormatInt(v, 10))
+		case float32:
+			row.number(ref, style, strconv.FormatFloat(float64(v), 'f', -1, 32))
+		case float64:
+			row.number(ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+		case time.Time:
+			if v.IsZero() {
+				continue
+			}
+
+			days := v.Sub(excelEpoch).Hours() / 24
+			row.number(ref, styleDate, strconv.FormatFloat(days, 'f', -1, 64))
+		case string:
+			row.inlineString(ref, style, v)
+		default:
+			row.inlineString(ref, style, fmt.Sprint(v))
+		}
+	}
+
+	row = append(row, "</row>"...)
+
+	_, err := w.sheet.Write(row)
+
+	return err
+}
+
+func columnName(index int) string {
+	name := ""
+
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+
+	return name
+}
+
+type xmlText []byte
+
+func (t *xmlText) write(value string) {
+	_ = xml.EscapeText(t, []byte(value))
+}
+
+func (t *xmlText) Write(p []byte) (int, error) {
+	*t = append(*t, p...)
+
+	return len(p), nil
+}
+
+func (t *xmlText) number(ref string, style int, value string) {
+	*t = append(*t, fmt.Sprintf(`<c r="%s"%s><v>%s</v></c>`, ref, styleAttribute(style), value)...)
+}
+
+func (t *xmlText) inlineString(ref string, style int, value string) {
+	runes := []rune(value)
+
+	if len(runes) > maxCellLength {
+		value = string(runes[:maxCellLength])
+	}
+
+	*t = append(*t, fmt.Sprintf(`<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">`, ref, styleAttribute(style))...)
+	t.write(value)
+	*t = append(*t, "</t></is></c>"...)
+}
+
+func styleAttribute(style int) string {
+	if style == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(` s="%d"`, style)
+}
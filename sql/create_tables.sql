@@ -23,6 +23,15 @@ SET default_table_access_method = heap;
 --
 -- Drops the tables so they can be recreated
 --
+DROP TABLE public.refresh_tokens;
+DROP TABLE public.password_resets;
+DROP TABLE public.login_attempts;
+DROP TABLE public.recovery_codes;
+DROP TABLE public.api_keys;
+DROP TABLE public.user_identities;
+DROP TABLE public.tmdb_cache;
+DROP TABLE public.import_job_errors;
+DROP TABLE public.import_jobs;
 DROP TABLE public.movies_genres;
 DROP TABLE public.genres;
 DROP TABLE public.movies;
@@ -35,6 +44,7 @@ DROP TABLE public.users;
 CREATE TABLE public.genres (
     id integer NOT NULL,
     genre character varying(255),
+    tmdb_id integer,
     created_at timestamp without time zone,
     updated_at timestamp without time zone
 );
@@ -69,7 +79,16 @@ CREATE TABLE public.movies (
     description text,
     image character varying(255),
     created_at timestamp without time zone,
-    updated_at timestamp without time zone
+    updated_at timestamp without time zone,
+    created_by integer,
+    updated_by integer,
+    tmdb_id integer,
+    poster_hash character varying(64),
+    poster_source character varying(255),
+    search_vector tsvector GENERATED ALWAYS AS (
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED
 );
 
 
@@ -112,6 +131,239 @@ ALTER TABLE public.movies ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
 );
 
 
+--
+-- Name: refresh_tokens; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.refresh_tokens (
+    id integer NOT NULL,
+    jti character varying(64) NOT NULL,
+    family_id character varying(64) NOT NULL,
+    user_id integer NOT NULL,
+    expires_at timestamp without time zone NOT NULL,
+    revoked_at timestamp without time zone,
+    replaced_by character varying(64),
+    created_at timestamp without time zone
+);
+
+
+--
+-- Name: refresh_tokens_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.refresh_tokens ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.refresh_tokens_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: password_resets; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.password_resets (
+    id integer NOT NULL,
+    user_id integer NOT NULL,
+    token_hash character varying(64) NOT NULL,
+    expires_at timestamp without time zone NOT NULL,
+    used_at timestamp without time zone,
+    created_at timestamp without time zone
+);
+
+
+--
+-- Name: password_resets_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.password_resets ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.password_resets_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: login_attempts; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.login_attempts (
+    key character varying(320) NOT NULL,
+    failures integer DEFAULT 0 NOT NULL,
+    last_failure_at timestamp without time zone NOT NULL,
+    locked_until timestamp without time zone
+);
+
+
+--
+-- Name: recovery_codes; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.recovery_codes (
+    id integer NOT NULL,
+    user_id integer NOT NULL,
+    code_hash character varying(64) NOT NULL,
+    used_at timestamp without time zone,
+    created_at timestamp without time zone
+);
+
+
+--
+-- Name: recovery_codes_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.recovery_codes ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.recovery_codes_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: api_keys; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.api_keys (
+    id integer NOT NULL,
+    user_id integer NOT NULL,
+    name character varying(255) NOT NULL,
+    prefix character varying(16) NOT NULL,
+    key_hash character varying(64) NOT NULL,
+    role character varying(20) NOT NULL,
+    expires_at timestamp without time zone,
+    last_used_at timestamp without time zone,
+    revoked_at timestamp without time zone,
+    created_at timestamp without time zone
+);
+
+
+--
+-- Name: api_keys_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.api_keys ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.api_keys_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: user_identities; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.user_identities (
+    id integer NOT NULL,
+    user_id integer NOT NULL,
+    issuer character varying(512) NOT NULL,
+    subject character varying(255) NOT NULL,
+    email character varying(255),
+    created_at timestamp without time zone
+);
+
+
+--
+-- Name: user_identities_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.user_identities ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.user_identities_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: tmdb_cache; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.tmdb_cache (
+    key character varying(600) NOT NULL,
+    value bytea NOT NULL,
+    expires_at timestamp without time zone NOT NULL
+);
+
+
+--
+-- Name: import_jobs; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.import_jobs (
+    id integer NOT NULL,
+    status character varying(20) NOT NULL,
+    params jsonb NOT NULL,
+    considered integer DEFAULT 0 NOT NULL,
+    skipped integer DEFAULT 0 NOT NULL,
+    imported integer DEFAULT 0 NOT NULL,
+    failed integer DEFAULT 0 NOT NULL,
+    attempt integer DEFAULT 0 NOT NULL,
+    lease_expires_at timestamp without time zone,
+    error text,
+    created_by integer,
+    created_at timestamp without time zone,
+    started_at timestamp without time zone,
+    finished_at timestamp without time zone
+);
+
+
+--
+-- Name: import_jobs_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.import_jobs ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.import_jobs_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
+--
+-- Name: import_job_errors; Type: TABLE; Schema: public; Owner: -
+--
+
+CREATE TABLE public.import_job_errors (
+    id integer NOT NULL,
+    job_id integer NOT NULL,
+    tmdb_id integer,
+    title character varying(512),
+    reason text NOT NULL,
+    attempt integer DEFAULT 1 NOT NULL,
+    created_at timestamp without time zone
+);
+
+
+--
+-- Name: import_job_errors_id_seq; Type: SEQUENCE; Schema: public; Owner: -
+--
+
+ALTER TABLE public.import_job_errors ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.import_job_errors_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+
+
 --
 -- Name: users; Type: TABLE; Schema: public; Owner: -
 --
@@ -122,6 +374,10 @@ CREATE TABLE public.users (
     last_name character varying(255),
     email character varying(255),
     password character varying(255),
+    role character varying(20) DEFAULT 'viewer'::character varying NOT NULL,
+    totp_secret text,
+    totp_enabled boolean DEFAULT false NOT NULL,
+    totp_last_step bigint,
     created_at timestamp without time zone,
     updated_at timestamp without time zone
 );
@@ -145,20 +401,20 @@ ALTER TABLE public.users ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
 -- Data for Name: genres; Type: TABLE DATA; Schema: public; Owner: -
 --
 
-COPY public.genres (id, genre, created_at, updated_at) FROM stdin;
-1	Comedy	2022-09-23 00:00:00	2022-09-23 00:00:00
-2	Sci-Fi	2022-09-23 00:00:00	2022-09-23 00:00:00
-3	Horror	2022-09-23 00:00:00	2022-09-23 00:00:00
-4	Romance	2022-09-23 00:00:00	2022-09-23 00:00:00
-5	Action	2022-09-23 00:00:00	2022-09-23 00:00:00
-6	Thriller	2022-09-23 00:00:00	2022-09-23 00:00:00
-7	Drama	2022-09-23 00:00:00	2022-09-23 00:00:00
-8	Mystery	2022-09-23 00:00:00	2022-09-23 00:00:00
-9	Crime	2022-09-23 00:00:00	2022-09-23 00:00:00
-10	Animation	2022-09-23 00:00:00	2022-09-23 00:00:00
-11	Adventure	2022-09-23 00:00:00	2022-09-23 00:00:00
-12	Fantasy	2022-09-23 00:00:00	2022-09-23 00:00:00
-13	Superhero	2022-09-23 00:00:00	2022-09-23 00:00:00
+COPY public.genres (id, genre, tmdb_id, created_at, updated_at) FROM stdin;
+1	Comedy	35	2022-09-23 00:00:00	2022-09-23 00:00:00
+2	Sci-Fi	878	2022-09-23 00:00:00	2022-09-23 00:00:00
+3	Horror	27	2022-09-23 00:00:00	2022-09-23 00:00:00
+4	Romance	10749	2022-09-23 00:00:00	2022-09-23 00:00:00
+5	Action	28	2022-09-23 00:00:00	2022-09-23 00:00:00
+6	Thriller	53	2022-09-23 00:00:00	2022-09-23 00:00:00
+7	Drama	18	2022-09-23 00:00:00	2022-09-23 00:00:00
+8	Mystery	9648	2022-09-23 00:00:00	2022-09-23 00:00:00
+9	Crime	80	2022-09-23 00:00:00	2022-09-23 00:00:00
+10	Animation	16	2022-09-23 00:00:00	2022-09-23 00:00:00
+11	Adventure	12	2022-09-23 00:00:00	2022-09-23 00:00:00
+12	Fantasy	14	2022-09-23 00:00:00	2022-09-23 00:00:00
+13	Superhero	\N	2022-09-23 00:00:00	2022-09-23 00:00:00
 \.
 
 
@@ -191,8 +447,8 @@ COPY public.movies_genres (id, movie_id, genre_id) FROM stdin;
 -- Data for Name: users; Type: TABLE DATA; Schema: public; Owner: -
 --
 
-COPY public.users (id, first_name, last_name, email, password, created_at, updated_at) FROM stdin;
-1	Admin	User	admin@example.com	$2a$14$wVsaPvJnJJsomWArouWCtusem6S/.Gauq/GjOIEHpyh2DAMmso1wy	2022-09-23 00:00:00	2022-09-23 00:00:00
+COPY public.users (id, first_name, last_name, email, password, role, created_at, updated_at) FROM stdin;
+1	Admin	User	admin@example.com	$2a$14$wVsaPvJnJJsomWArouWCtusem6S/.Gauq/GjOIEHpyh2DAMmso1wy	admin	2022-09-23 00:00:00	2022-09-23 00:00:00
 \.
 
 
@@ -232,6 +488,14 @@ ALTER TABLE ONLY public.genres
     ADD CONSTRAINT genres_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: genres genres_tmdb_id_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.genres
+    ADD CONSTRAINT genres_tmdb_id_key UNIQUE (tmdb_id);
+
+
 --
 -- Name: movies_genres movies_genres_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -248,6 +512,160 @@ ALTER TABLE ONLY public.movies
     ADD CONSTRAINT movies_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: movies movies_tmdb_id_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.movies
+    ADD CONSTRAINT movies_tmdb_id_key UNIQUE (tmdb_id);
+
+
+--
+-- Name: movies_search_vector_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX movies_search_vector_idx ON public.movies USING gin (search_vector);
+
+
+--
+-- Name: refresh_tokens refresh_tokens_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT refresh_tokens_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: refresh_tokens refresh_tokens_jti_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT refresh_tokens_jti_key UNIQUE (jti);
+
+
+--
+-- Name: refresh_tokens_family_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX refresh_tokens_family_id_idx ON public.refresh_tokens USING btree (family_id);
+
+
+--
+-- Name: password_resets password_resets_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.password_resets
+    ADD CONSTRAINT password_resets_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: password_resets password_resets_token_hash_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.password_resets
+    ADD CONSTRAINT password_resets_token_hash_key UNIQUE (token_hash);
+
+
+--
+-- Name: login_attempts login_attempts_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.login_attempts
+    ADD CONSTRAINT login_attempts_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: recovery_codes recovery_codes_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.recovery_codes
+    ADD CONSTRAINT recovery_codes_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: recovery_codes_user_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX recovery_codes_user_id_idx ON public.recovery_codes USING btree (user_id);
+
+
+--
+-- Name: api_keys api_keys_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.api_keys
+    ADD CONSTRAINT api_keys_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: api_keys api_keys_key_hash_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.api_keys
+    ADD CONSTRAINT api_keys_key_hash_key UNIQUE (key_hash);
+
+
+--
+-- Name: user_identities user_identities_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_identities
+    ADD CONSTRAINT user_identities_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: user_identities user_identities_issuer_subject_key; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_identities
+    ADD CONSTRAINT user_identities_issuer_subject_key UNIQUE (issuer, subject);
+
+
+--
+-- Name: tmdb_cache tmdb_cache_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.tmdb_cache
+    ADD CONSTRAINT tmdb_cache_pkey PRIMARY KEY (key);
+
+
+--
+-- Name: tmdb_cache_expires_at_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX tmdb_cache_expires_at_idx ON public.tmdb_cache USING btree (expires_at);
+
+
+--
+-- Name: import_jobs import_jobs_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.import_jobs
+    ADD CONSTRAINT import_jobs_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: import_jobs_status_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX import_jobs_status_idx ON public.import_jobs USING btree (status);
+
+
+--
+-- Name: import_job_errors import_job_errors_pkey; Type: CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.import_job_errors
+    ADD CONSTRAINT import_job_errors_pkey PRIMARY KEY (id);
+
+
+--
+-- Name: import_job_errors_job_id_idx; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE INDEX import_job_errors_job_id_idx ON public.import_job_errors USING btree (job_id);
+
+
 --
 -- Name: users users_pkey; Type: CONSTRAINT; Schema: public; Owner: -
 --
@@ -256,6 +674,13 @@ ALTER TABLE ONLY public.users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
 
+--
+-- Name: users_email_key; Type: INDEX; Schema: public; Owner: -
+--
+
+CREATE UNIQUE INDEX users_email_key ON public.users USING btree (lower((email)::text));
+
+
 --
 -- Name: movies_genres movies_genres_genre_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
 --
@@ -272,6 +697,78 @@ ALTER TABLE ONLY public.movies_genres
     ADD CONSTRAINT movies_genres_movie_id_fkey FOREIGN KEY (movie_id) REFERENCES public.movies(id) ON UPDATE CASCADE ON DELETE CASCADE;
 
 
+--
+-- Name: movies movies_created_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.movies
+    ADD CONSTRAINT movies_created_by_fkey FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: movies movies_updated_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.movies
+    ADD CONSTRAINT movies_updated_by_fkey FOREIGN KEY (updated_by) REFERENCES public.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: refresh_tokens refresh_tokens_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.refresh_tokens
+    ADD CONSTRAINT refresh_tokens_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: password_resets password_resets_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.password_resets
+    ADD CONSTRAINT password_resets_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: recovery_codes recovery_codes_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.recovery_codes
+    ADD CONSTRAINT recovery_codes_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: api_keys api_keys_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.api_keys
+    ADD CONSTRAINT api_keys_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: user_identities user_identities_user_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.user_identities
+    ADD CONSTRAINT user_identities_user_id_fkey FOREIGN KEY (user_id) REFERENCES public.users(id) ON DELETE CASCADE;
+
+
+--
+-- Name: import_jobs import_jobs_created_by_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.import_jobs
+    ADD CONSTRAINT import_jobs_created_by_fkey FOREIGN KEY (created_by) REFERENCES public.users(id) ON DELETE SET NULL;
+
+
+--
+-- Name: import_job_errors import_job_errors_job_id_fkey; Type: FK CONSTRAINT; Schema: public; Owner: -
+--
+
+ALTER TABLE ONLY public.import_job_errors
+    ADD CONSTRAINT import_job_errors_job_id_fkey FOREIGN KEY (job_id) REFERENCES public.import_jobs(id) ON DELETE CASCADE;
+
+
 --
 -- PostgreSQL database dump complete
 --